@@ -4,35 +4,65 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/orders_api/api/errs"
 	"github.com/orders_api/api/handlers"
 	"github.com/orders_api/api/routes"
 	"github.com/orders_api/internal/config"
 	"github.com/orders_api/internal/database/cache"
 	"github.com/orders_api/internal/database/postgres"
+	"github.com/orders_api/internal/health"
 	"github.com/orders_api/internal/kafka"
+	"github.com/orders_api/internal/metrics"
 	"github.com/orders_api/internal/repository"
 	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/tracing"
 	segmentio "github.com/segmentio/kafka-go"
 )
 
 type App struct {
-	FiberApp    *fiber.App
-	Srvc        service.ServiceOrder
-	Db          *pgx.Conn
-	Cfg         *config.Config
-	KafkaReader *segmentio.Reader
-	Consumer    *kafka.KafkaConsumer
+	FiberApp     *fiber.App
+	Srvc         service.ServiceOrder
+	Db           *pgxpool.Pool
+	Replicas     *postgres.Replicas
+	Cfg          *config.Config
+	KafkaReader  *segmentio.Reader
+	DLQWriter    *segmentio.Writer
+	EventsWriter *segmentio.Writer
+	Consumer     *kafka.KafkaConsumer
+	OutboxRelay  *kafka.OutboxRelay
+	Cache        cache.Cache
+
+	// завершение прогрева кэша, до него сервис не готов принимать трафик
+	CacheWarmedUp *health.Flag
+
+	shutdownTracing func(context.Context) error
+	background      sync.WaitGroup
 }
 
 func InitNewFiberApp(cfg *config.Config, ctx context.Context) *App {
 
+	// трассировка нужна с самого начала, чтобы в трассы попали и запросы прогрева кэша
+	shutdownTracing, err := tracing.Init(ctx, &cfg.Tracing)
+	if err != nil {
+		slog.Error("Failed to init tracing",
+			"exporter", cfg.Tracing.Exporter,
+			"error", err)
+		os.Exit(1)
+	}
+
 	// подключаемся к БД
-	db, err := postgres.NewPostgresDB(ctx, &cfg.Postgres)
+	db, err := postgres.NewPostgresDB(ctx, &cfg.Postgres, queryTracer)
 	if err != nil {
 		slog.Error("Failed connect to postgres DB",
 			"error", err)
@@ -49,20 +79,41 @@ func InitNewFiberApp(cfg *config.Config, ctx context.Context) *App {
 	}
 	slog.Info("Successfully ran migratons")
 
-	// создаем репозиторий и кэш для сервиса
-	repOrder := repository.NewOrderPostgresRepository(db)
-	cacheOrder := cache.NewOrderCacher()
+	// версия схемы, которую /readyz ожидает увидеть в БД
+	expectedMigration, err := postgres.LatestMigrationVersion()
+	if err != nil {
+		slog.Error("Failed read migrations",
+			"error", err)
+		os.Exit(1)
+	}
 
-	// создаем сервис обработки заказов
-	serviceOrder := service.NewServiceOrder(repOrder, cacheOrder, ctx)
+	// подключаемся к репликам для чтения, если они заданы
+	replicas, err := postgres.NewReplicas(ctx, &cfg.Postgres, queryTracer)
+	if err != nil {
+		slog.Error("Failed connect to postgres replicas",
+			"error", err)
+		os.Exit(1)
+	}
 
-	// при старте сервиса загрузим все актуальные данные из БД в кэш
-	err = serviceOrder.Recover()
+	// создаем репозиторий и кэш для сервиса
+	repOrder := repository.NewOrderPostgresRepository(db)
+	if replicas != nil {
+		repOrder.UseReplicas(replicas)
+		slog.Info("Reading orders from replicas", "replicas", cfg.Postgres.Replicas, "max_lag", cfg.Postgres.ReplicaMaxLag)
+	}
+	cacheOrder, err := cache.New(&cfg.Cache)
 	if err != nil {
-		slog.Info("Started with empty cache")
-	} else {
-		slog.Info("Successfully loaded data to cache")
+		slog.Error("Failed to create order cache",
+			"backend", cfg.Cache.Backend,
+			"error", err)
+		os.Exit(1)
 	}
+	cacheOrder.OnInvalidate(func(id uuid.UUID, reason string) {
+		slog.Debug("order removed from cache", "order_uuid", id, "reason", reason)
+	})
+
+	// создаем сервис обработки заказов
+	serviceOrder := service.NewServiceOrder(repOrder, cacheOrder, cfg.Cache.NotFoundTTL, ctx)
 
 	// подключим kafka Reader
 	kafkaReader, err := kafka.NewReader(&cfg.Kafka)
@@ -73,33 +124,86 @@ func InitNewFiberApp(cfg *config.Config, ctx context.Context) *App {
 	}
 	slog.Info("Successfully ran kafka", "brokers", cfg.Kafka.Address, "topic", cfg.Kafka.Topic, "group", cfg.Kafka.Group)
 
+	// подключим Writer для dead-letter топика
+	dlqWriter := kafka.NewDLQWriter(&cfg.Kafka)
+
 	// подключим Consumer
-	consumer := kafka.NewKafkaConsumer(kafkaReader, &cfg.Kafka, serviceOrder)
+	consumer := kafka.NewKafkaConsumer(kafkaReader, dlqWriter, &cfg.Kafka, serviceOrder)
+
+	// подключим relay, публикующий события заказов из outbox; соединения он берет из общего пула
+	eventsWriter := kafka.NewEventsWriter(&cfg.Kafka)
+	outboxRelay := kafka.NewOutboxRelay(eventsWriter, repOrder, &cfg.Kafka)
+
+	// метрики компонентов снимаются при каждом запросе /metrics
+	metrics.Registry.MustRegister(
+		metrics.NewDBCollector(repOrder.DBStats),
+		metrics.NewCacheCollector(cfg.Cache.Backend, cacheOrder.Stats),
+		metrics.NewKafkaReaderCollector(kafkaReader.Stats),
+	)
+
+	// проверки готовности для /readyz
+	cacheWarmedUp := &health.Flag{}
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Add("postgres", db.Ping)
+	checker.Add("kafka_broker", consumer.CheckBroker)
+	checker.Add("kafka_consumer_group", consumer.CheckGroupMembership)
+	checker.Add("cache_warmup", cacheWarmedUp.Check)
+	checker.Add("migrations", func(ctx context.Context) error {
+		return postgres.CheckMigrations(ctx, db, expectedMigration)
+	})
 
 	// создаем новый FiberApp
+	// тело POST /orders:bulk читается потоком, а не загружается в память целиком; тела остальных запросов
+	// при потоковом чтении ограничивает LimitBody, иначе BodyLimit на них бы не действовал.
+	// Ошибки самого Fiber (неизвестный адрес, слишком большое тело) отдаются в том же формате, что и ошибки обработчиков
 	app := fiber.New(fiber.Config{
-		Prefork: false,
+		Prefork:           false,
+		StreamRequestBody: true,
+		ErrorHandler:      errs.ErrorHandler,
 	})
+	app.Use(errs.Middleware(cfg.Errors), metrics.HTTPMiddleware(), tracing.HTTPMiddleware())
+	app.Use(handlers.LimitBody(func(c *fiber.Ctx) bool {
+		return c.Method() == fiber.MethodPost && c.Path() == "/orders:bulk"
+	}))
+	app.Get("/metrics", metrics.Handler())
 
 	// подключим хэндлер заказов
 	orderHandler := handlers.NewOrderHandler(serviceOrder)
 
 	// подключаем роуты
+	routes.InitRoutesForHealth(app, handlers.NewHealthHandler(checker))
+	routes.InitRoutesForProblems(app, handlers.NewProblemHandler())
+	routes.InitRoutesForExport(app, handlers.NewExportHandler(serviceOrder, &cfg.Export))
 	routes.InitRoutesForOrders(app, orderHandler)
+	routes.InitRoutesForBulk(app, handlers.NewBulkHandler(serviceOrder, &cfg.Bulk))
 	routes.InitRouteForSwagger(app)
 
 	app.Static("/", "assets")
 
 	return &App{
-		FiberApp:    app,
-		Db:          db,
-		Srvc:        serviceOrder,
-		Cfg:         cfg,
-		KafkaReader: kafkaReader,
-		Consumer:    consumer,
+		FiberApp:     app,
+		Db:           db,
+		Replicas:     replicas,
+		Srvc:         serviceOrder,
+		Cfg:          cfg,
+		KafkaReader:  kafkaReader,
+		DLQWriter:    dlqWriter,
+		EventsWriter: eventsWriter,
+		Consumer:     consumer,
+		OutboxRelay:  outboxRelay,
+		Cache:        cacheOrder,
+
+		CacheWarmedUp: cacheWarmedUp,
+
+		shutdownTracing: shutdownTracing,
 	}
 }
 
+// queryTracer снимает метрики и открывает спаны по запросам к серверу БД server
+func queryTracer(server string) pgx.QueryTracer {
+	return multitracer.New(metrics.NewQueryTracer(server), tracing.NewQueryTracer(server))
+}
+
 func (a *App) Start(ctx context.Context) {
 	slog.Info("App staring", "port", a.Cfg.ServerPort)
 
@@ -112,33 +216,166 @@ func (a *App) Start(ctx context.Context) {
 		}
 	}()
 
-	go a.Consumer.ReadMessages(ctx)
-	slog.Info("Consumer started")
+	// фоновые горутины отслеживаем, чтобы при остановке дождаться их завершения.
+	// Кэш прогревается, пока сервер уже отвечает на /healthz; консьюмер начинает чтение после прогрева
+	a.background.Add(1)
+	go func() {
+		defer a.background.Done()
+		a.warmUpCache()
+		a.Consumer.ReadMessages(ctx)
+	}()
+	slog.Info("Consumer started", "workers", a.Cfg.Kafka.Workers)
+
+	a.background.Add(1)
+	go func() {
+		defer a.background.Done()
+		a.OutboxRelay.Run(ctx)
+	}()
+	slog.Info("Outbox relay started", "topic", a.Cfg.Kafka.EventsTopic)
+
+	// проверка доступности и отставания реплик
+	if a.Replicas != nil {
+		a.background.Add(1)
+		go func() {
+			defer a.background.Done()
+			a.Replicas.Run(ctx)
+		}()
+	}
+
+	// janitor локального кэша и подписка на инвалидации общего
+	if bg, ok := a.Cache.(cache.Background); ok {
+		a.background.Add(1)
+		go func() {
+			defer a.background.Done()
+			bg.Run(ctx)
+		}()
+		slog.Info("Cache background started", "backend", a.Cfg.Cache.Backend)
+	}
+
+	if s, ok := a.Cache.(cache.Snapshotter); ok && a.Cfg.Cache.SnapshotPath != "" && a.Cfg.Cache.SnapshotInterval > 0 {
+		a.background.Add(1)
+		go func() {
+			defer a.background.Done()
+			cache.RunSnapshots(ctx, s, a.Cfg.Cache.SnapshotPath, a.Cfg.Cache.SnapshotInterval)
+		}()
+		slog.Info("Cache snapshots started", "path", a.Cfg.Cache.SnapshotPath, "interval", a.Cfg.Cache.SnapshotInterval)
+	}
 
 }
 
 func (a *App) Stop(ctx context.Context) error {
 	slog.Info("[!] Shutting down...")
 
-	var stopErr = errors.New("")
+	var stopErr error
 
-	// закрываем соединение БД
-	if err := postgres.ClosePostgresDB(ctx, a.Db); err != nil {
-		errors.Join(stopErr, err)
+	// дождемся, пока воркеры консьюмера доделают текущие сообщения и закоммитят offsets
+	if err := a.waitBackground(ctx); err != nil {
+		stopErr = errors.Join(stopErr, err)
+	}
+
+	// закрываем соединение с сервером
+	if err := a.FiberApp.ShutdownWithContext(ctx); err != nil {
+		stopErr = errors.Join(stopErr, err)
 	}
 
 	// закрываем kafky
 	if err := a.KafkaReader.Close(); err != nil {
-		errors.Join(stopErr, err)
+		stopErr = errors.Join(stopErr, err)
 	}
 
-	// закрываем соединение с сервером
-	if err := a.FiberApp.ShutdownWithContext(ctx); err != nil {
-		errors.Join(stopErr, err)
+	if err := a.DLQWriter.Close(); err != nil {
+		stopErr = errors.Join(stopErr, err)
 	}
 
-	if stopErr.Error() != "" {
-		return stopErr
+	if err := a.EventsWriter.Close(); err != nil {
+		stopErr = errors.Join(stopErr, err)
+	}
+
+	// сохраним кэш, чтобы следующий запуск начался с теплым кэшем
+	if s, ok := a.Cache.(cache.Snapshotter); ok && a.Cfg.Cache.SnapshotPath != "" {
+		if err := s.SaveSnapshot(a.Cfg.Cache.SnapshotPath); err != nil {
+			stopErr = errors.Join(stopErr, err)
+		}
+	}
+
+	// закрываем соединение с общим кэшем
+	if closer, ok := a.Cache.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			stopErr = errors.Join(stopErr, err)
+		}
+	}
+
+	// закрываем пулы соединений БД
+	if a.Replicas != nil {
+		a.Replicas.Close()
+	}
+	postgres.ClosePostgresDB(a.Db)
+
+	// отправим оставшиеся спаны
+	if err := a.shutdownTracing(ctx); err != nil {
+		stopErr = errors.Join(stopErr, err)
+	}
+
+	return stopErr
+}
+
+// warmUpCache загружает в кэш снапшот или последние заказы; общий кэш уже могла прогреть другая реплика.
+// После прогрева сервис становится готовым, даже если загрузить заказы не удалось
+func (a *App) warmUpCache() {
+	defer a.CacheWarmedUp.Done()
+
+	if loadSnapshot(a.Cache, a.Srvc, &a.Cfg.Cache) {
+		slog.Info("Successfully loaded cache snapshot")
+	} else if !cache.NeedsWarmup(a.Cache) {
+		slog.Info("Shared cache is warmed up by another replica")
+	} else if err := a.Srvc.Recover(a.Cfg.Cache.WarmupOrders); err != nil {
+		slog.Info("Started with empty cache")
+	} else {
+		slog.Info("Successfully loaded data to cache")
+	}
+}
+
+// loadSnapshot загружает локальный кэш из снапшота и догружает изменения, сделанные после него
+func loadSnapshot(c cache.Cache, srvc service.ServiceOrder, cfg *cache.CacheConfig) bool {
+	s, ok := c.(cache.Snapshotter)
+	if !ok || cfg.SnapshotPath == "" {
+		return false
+	}
+
+	info, err := s.LoadSnapshot(cfg.SnapshotPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	if err != nil {
+		slog.Warn("Failed to load cache snapshot", "path", cfg.SnapshotPath, "error", err)
+		return false
+	}
+
+	err = srvc.RecoverSince(info.Watermark, info.TakenAt, cfg.WarmupOrders)
+	if err != nil {
+		// заказы из снапшота могли устареть, отдавать их нельзя
+		for _, order := range info.Orders {
+			c.Delete(order)
+		}
+		slog.Warn("Failed to catch up cache snapshot", "error", err)
+		return false
+	}
+
+	slog.Info("Cache snapshot restored", "orders", len(info.Orders), "taken_at", info.TakenAt, "watermark", info.Watermark)
+	return true
+}
+
+func (a *App) waitBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[Stop| wait background workers]: %w", ctx.Err())
 	}
-	return nil
 }
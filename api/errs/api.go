@@ -1,56 +1,83 @@
 package errs
 
-// ErrorResponse модель возвращаемой ошибки
-// @Description Модель описывает возвращаемую ошибку: код и краткое сообщение
+import "github.com/orders_api/internal/models"
+
+// ErrorResponse модель возвращаемой ошибки в режиме совместимости (ERRORS_FORMAT=legacy)
+// @Description Модель описывает возвращаемую ошибку: код, краткое сообщение и поля запроса, не прошедшие проверку
 type ErrorResponse struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
+	Code   int                 `json:"code"`
+	Msg    string              `json:"msg"`
+	Fields []models.FieldError `json:"fields,omitempty"`
+}
+
+// Problem модель возвращаемой ошибки в формате RFC 7807 (application/problem+json)
+// @Description Тип ошибки (URI ее описания), заголовок на языке из Accept-Language, HTTP статус, путь запроса,
+// @Description стабильный машиночитаемый код и поля запроса, не прошедшие проверку
+type Problem struct {
+	Type     string              `json:"type" example:"/problems/order_not_found"`
+	Title    string              `json:"title" example:"Order not found"`
+	Status   int                 `json:"status" example:"404"`
+	Instance string              `json:"instance,omitempty" example:"/orders/f47ac10b-58cc-4372-a567-0e02b2c3d479"`
+	Code     string              `json:"code" example:"order_not_found"`
+	Fields   []models.FieldError `json:"fields,omitempty"`
+}
+
+// APIError ошибка API: HTTP статус и стабильный код, по которому из каталога берется заголовок на языке клиента
+type APIError struct {
+	Status int
+	Code   string
+	Fields []models.FieldError
+}
+
+// WithFields копия ошибки со списком полей, не прошедших проверку
+func (e APIError) WithFields(fields []models.FieldError) APIError {
+	e.Fields = fields
+	return e
 }
 
 const (
 	BadRequestCode          = 400
 	NotFoundCode            = 404
+	MethodNotAllowedCode    = 405
+	NotAcceptableCode       = 406
+	ConflictCode            = 409
+	PayloadTooLargeCode     = 413
+	UnprocessableEntityCode = 422
+	TooManyRequestsCode     = 429
 	InternalServerErrorCode = 500
 )
 
+// registry все ошибки API по коду: по нему отдается описание типа ошибки
+var registry = make(map[string]APIError)
+
+func newError(status int, code string) APIError {
+	e := APIError{Status: status, Code: code}
+	registry[code] = e
+	return e
+}
+
+// коды ошибок - часть API: клиенты сверяются с ними, поэтому существующие коды не переименовываются
 var (
-	ErrInternalServer = ErrorResponse{
-		Code: InternalServerErrorCode,
-		Msg:  "внутренняя ошибка сервера при исполнении запроса",
-	}
-
-	ErrInvalidJSON = ErrorResponse{
-		Code: BadRequestCode,
-		Msg:  "Неверный формат данных",
-	}
-
-	ErrValidateJSON = ErrorResponse{
-		Code: BadRequestCode,
-		Msg:  "Неверно указаны данные",
-	}
-
-	ErrOrderNotFound = ErrorResponse{
-		Code: NotFoundCode,
-		Msg:  "заказ не найден",
-	}
-
-	ErrInvalidUUID = ErrorResponse{
-		Code: BadRequestCode,
-		Msg:  "Неверный формат uuid",
-	}
-
-	ErrOrderExistsUUID = ErrorResponse{
-		Code: BadRequestCode,
-		Msg:  "заказ с таким uuid уже существует",
-	}
-
-	ErrOrderExistsTrack = ErrorResponse{
-		Code: BadRequestCode,
-		Msg:  "заказ с таким трек номером уже существует",
-	}
-
-	ErrPaymentExists = ErrorResponse{
-		Code: BadRequestCode,
-		Msg:  "платеж с такой транзакцией уже существует",
-	}
+	ErrInternalServer          = newError(InternalServerErrorCode, "internal_error")
+	ErrInvalidJSON             = newError(BadRequestCode, "invalid_json")
+	ErrValidateJSON            = newError(BadRequestCode, "validation_failed")
+	ErrOrderNotFound           = newError(NotFoundCode, "order_not_found")
+	ErrInvalidUUID             = newError(BadRequestCode, "invalid_uuid")
+	ErrOrderExistsUUID         = newError(BadRequestCode, "order_exists")
+	ErrOrderExistsTrack        = newError(BadRequestCode, "track_number_exists")
+	ErrInvalidListQuery        = newError(BadRequestCode, "invalid_list_query")
+	ErrInvalidCursor           = newError(BadRequestCode, "invalid_cursor")
+	ErrInvalidStatus           = newError(BadRequestCode, "invalid_status")
+	ErrInvalidStatusTransition = newError(ConflictCode, "invalid_status_transition")
+	ErrStatusConflict          = newError(ConflictCode, "status_conflict")
+	ErrIdempotencyKeyReused    = newError(UnprocessableEntityCode, "idempotency_key_reused")
+	ErrBulkBusy                = newError(TooManyRequestsCode, "bulk_busy")
+	ErrExportFormat            = newError(NotAcceptableCode, "export_format_not_acceptable")
+	ErrExportBusy              = newError(TooManyRequestsCode, "export_busy")
+
+	// ошибки маршрутизации и разбора запроса, которые возвращает сам Fiber
+	ErrRouteNotFound    = newError(NotFoundCode, "route_not_found")
+	ErrMethodNotAllowed = newError(MethodNotAllowedCode, "method_not_allowed")
+	ErrPayloadTooLarge  = newError(PayloadTooLargeCode, "payload_too_large")
+	ErrBadRequest       = newError(BadRequestCode, "bad_request")
 )
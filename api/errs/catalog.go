@@ -0,0 +1,60 @@
+package errs
+
+// языки каталогов; первый используется, если клиент не передал Accept-Language или просит язык, которого нет
+var languages = []string{"ru", "en"}
+
+// titles заголовки ошибок по языку и коду ошибки
+var titles = map[string]map[string]string{
+	"ru": {
+		"internal_error":               "внутренняя ошибка сервера при исполнении запроса",
+		"invalid_json":                 "Неверный формат данных",
+		"validation_failed":            "Неверно указаны данные",
+		"order_not_found":              "заказ не найден",
+		"invalid_uuid":                 "Неверный формат uuid",
+		"order_exists":                 "заказ с таким uuid уже существует",
+		"track_number_exists":          "заказ с таким трек номером уже существует",
+		"invalid_list_query":           "Неверно указаны параметры списка заказов",
+		"invalid_cursor":               "Неверный курсор страницы",
+		"invalid_status":               "неизвестный статус заказа",
+		"invalid_status_transition":    "недопустимый переход статуса заказа",
+		"status_conflict":              "статус заказа был изменен одновременно другим запросом",
+		"idempotency_key_reused":       "ключ идемпотентности уже использован для другого заказа",
+		"bulk_busy":                    "слишком много одновременных импортов, повторите запрос позже",
+		"export_format_not_acceptable": "неподдерживаемый формат выгрузки, доступны csv, ndjson и parquet",
+		"export_busy":                  "слишком много одновременных выгрузок, повторите запрос позже",
+		"route_not_found":              "адрес не найден",
+		"method_not_allowed":           "метод не поддерживается для этого адреса",
+		"payload_too_large":            "слишком большое тело запроса",
+		"bad_request":                  "некорректный запрос",
+	},
+	"en": {
+		"internal_error":               "Internal server error while processing the request",
+		"invalid_json":                 "Malformed request body",
+		"validation_failed":            "Invalid field values",
+		"order_not_found":              "Order not found",
+		"invalid_uuid":                 "Malformed uuid",
+		"order_exists":                 "An order with this uuid already exists",
+		"track_number_exists":          "An order with this track number already exists",
+		"invalid_list_query":           "Invalid order list parameters",
+		"invalid_cursor":               "Invalid page cursor",
+		"invalid_status":               "Unknown order status",
+		"invalid_status_transition":    "Order status transition is not allowed",
+		"status_conflict":              "Order status was changed concurrently by another request",
+		"idempotency_key_reused":       "Idempotency key was already used for another order",
+		"bulk_busy":                    "Too many concurrent imports, retry later",
+		"export_format_not_acceptable": "Unsupported export format, use csv, ndjson or parquet",
+		"export_busy":                  "Too many concurrent exports, retry later",
+		"route_not_found":              "Route not found",
+		"method_not_allowed":           "Method not allowed for this route",
+		"payload_too_large":            "Request body is too large",
+		"bad_request":                  "Bad request",
+	},
+}
+
+// title заголовок ошибки с кодом code на языке lang
+func title(lang, code string) string {
+	if t, ok := titles[lang][code]; ok {
+		return t
+	}
+	return titles[languages[0]][code]
+}
@@ -0,0 +1,106 @@
+package errs
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// форматы ответов об ошибках
+const (
+	FormatProblem = "problem" // RFC 7807, application/problem+json
+	FormatLegacy  = "legacy"  // прежний {code, msg} для существующих клиентов
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Config формат ответов об ошибках
+type Config struct {
+	Format      string `env:"ERRORS_FORMAT" envDefault:"problem"`           // problem или legacy
+	TypeBaseURI string `env:"ERRORS_TYPE_BASE_URI" envDefault:"/problems/"` // type ошибки - этот URI с кодом ошибки на конце
+}
+
+var defaultConfig = Config{Format: FormatProblem, TypeBaseURI: "/problems/"}
+
+type configKey struct{}
+
+// Middleware передает настройки формата ошибок в обработку запроса; без него используется формат problem
+func Middleware(cfg Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(configKey{}, cfg)
+		return c.Next()
+	}
+}
+
+func config(c *fiber.Ctx) Config {
+	if cfg, ok := c.Locals(configKey{}).(Config); ok {
+		return cfg
+	}
+	return defaultConfig
+}
+
+// Language язык ответа из Accept-Language среди языков каталога
+func Language(c *fiber.Ctx) string {
+	if lang := c.AcceptsLanguages(languages...); lang != "" {
+		return lang
+	}
+	return languages[0]
+}
+
+// Lookup ошибка API по ее коду
+func Lookup(code string) (APIError, bool) {
+	e, ok := registry[code]
+	return e, ok
+}
+
+// NewProblem описание ошибки e на языке запроса c
+func NewProblem(c *fiber.Ctx, e APIError) Problem {
+	return Problem{
+		Type:   config(c).TypeBaseURI + e.Code,
+		Title:  title(Language(c), e.Code),
+		Status: e.Status,
+		Code:   e.Code,
+		Fields: e.Fields,
+	}
+}
+
+// Send отвечает ошибкой e в формате из настроек: RFC 7807 или, в режиме совместимости, прежним {code, msg}.
+// Заголовок и сообщение выбираются по Accept-Language
+func Send(c *fiber.Ctx, e APIError) error {
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentLanguage, Language(c))
+	c.Status(e.Status)
+
+	problem := NewProblem(c, e)
+	if config(c).Format == FormatLegacy {
+		return c.JSON(ErrorResponse{Code: problem.Status, Msg: problem.Title, Fields: problem.Fields})
+	}
+
+	problem.Instance = c.Path()
+	return c.JSON(problem, MIMEApplicationProblemJSON)
+}
+
+// ErrorHandler отвечает в том же формате на ошибки, которые вернул сам Fiber или обработчик вместо ответа
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		slog.Error("unhandled request error", "path", c.Path(), "error", err)
+		return Send(c, ErrInternalServer)
+	}
+
+	switch {
+	case fiberErr.Code == NotFoundCode:
+		return Send(c, ErrRouteNotFound)
+	case fiberErr.Code == MethodNotAllowedCode:
+		return Send(c, ErrMethodNotAllowed)
+	case fiberErr.Code == PayloadTooLargeCode:
+		return Send(c, ErrPayloadTooLarge)
+	case fiberErr.Code < InternalServerErrorCode:
+		// статус ответа сохраняем, тип ошибки - общий для некорректных запросов
+		return Send(c, APIError{Status: fiberErr.Code, Code: ErrBadRequest.Code})
+	default:
+		slog.Error("request failed", "path", c.Path(), "status", fiberErr.Code, "error", err)
+		return Send(c, ErrInternalServer)
+	}
+}
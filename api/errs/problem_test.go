@@ -0,0 +1,135 @@
+package errs
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogs_CoverAllErrors(t *testing.T) {
+	for code := range registry {
+		for _, lang := range languages {
+			assert.NotEmpty(t, titles[lang][code], "%s: no %s title", code, lang)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	fields := []models.FieldError{{Field: "items[1].sale", Rule: "lte", Param: "100", Message: "must be less than or equal to 100"}}
+	handler := func(c *fiber.Ctx) error {
+		return Send(c, ErrValidateJSON.WithFields(fields))
+	}
+
+	problemApp := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	problemApp.Post("/orders", handler)
+
+	legacyApp := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	legacyApp.Use(Middleware(Config{Format: FormatLegacy}))
+	legacyApp.Post("/orders", handler)
+
+	tests := []struct {
+		Name                string
+		App                 *fiber.App
+		Method              string
+		Path                string
+		AcceptLanguage      string
+		ExpectedStatus      int
+		ExpectedContentType string
+		ExpectedLanguage    string
+		ExpectedBody        string
+	}{
+		{
+			Name:                "Problem_default_language",
+			App:                 problemApp,
+			Method:              fiber.MethodPost,
+			Path:                "/orders",
+			ExpectedStatus:      http.StatusBadRequest,
+			ExpectedContentType: MIMEApplicationProblemJSON,
+			ExpectedLanguage:    "ru",
+			ExpectedBody: `{
+		"type":     "/problems/validation_failed",
+		"title":    "Неверно указаны данные",
+		"status":   400,
+		"instance": "/orders",
+		"code":     "validation_failed",
+		"fields":   [{"field": "items[1].sale", "rule": "lte", "param": "100", "message": "must be less than or equal to 100"}]
+	}`,
+		},
+		{
+			Name:                "Problem_english",
+			App:                 problemApp,
+			Method:              fiber.MethodPost,
+			Path:                "/orders",
+			AcceptLanguage:      "de;q=1, en-US;q=0.8, ru;q=0.5",
+			ExpectedStatus:      http.StatusBadRequest,
+			ExpectedContentType: MIMEApplicationProblemJSON,
+			ExpectedLanguage:    "en",
+			ExpectedBody: `{
+		"type":     "/problems/validation_failed",
+		"title":    "Invalid field values",
+		"status":   400,
+		"instance": "/orders",
+		"code":     "validation_failed",
+		"fields":   [{"field": "items[1].sale", "rule": "lte", "param": "100", "message": "must be less than or equal to 100"}]
+	}`,
+		},
+		{
+			Name:                "Legacy_shape",
+			App:                 legacyApp,
+			Method:              fiber.MethodPost,
+			Path:                "/orders",
+			ExpectedStatus:      http.StatusBadRequest,
+			ExpectedContentType: fiber.MIMEApplicationJSON,
+			ExpectedLanguage:    "ru",
+			ExpectedBody: `{
+		"code":   400,
+		"msg":    "Неверно указаны данные",
+		"fields": [{"field": "items[1].sale", "rule": "lte", "param": "100", "message": "must be less than or equal to 100"}]
+	}`,
+		},
+		{
+			Name:                "Fiber_route_not_found",
+			App:                 problemApp,
+			Method:              fiber.MethodGet,
+			Path:                "/unknown",
+			AcceptLanguage:      "en",
+			ExpectedStatus:      http.StatusNotFound,
+			ExpectedContentType: MIMEApplicationProblemJSON,
+			ExpectedLanguage:    "en",
+			ExpectedBody: `{
+		"type":     "/problems/route_not_found",
+		"title":    "Route not found",
+		"status":   404,
+		"instance": "/unknown",
+		"code":     "route_not_found"
+	}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.Method, tt.Path, nil)
+			if tt.AcceptLanguage != "" {
+				req.Header.Set(fiber.HeaderAcceptLanguage, tt.AcceptLanguage)
+			}
+
+			resp, err := tt.App.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.ExpectedContentType, resp.Header.Get(fiber.HeaderContentType))
+			assert.Equal(t, tt.ExpectedLanguage, resp.Header.Get(fiber.HeaderContentLanguage))
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.ExpectedBody, string(body))
+		})
+	}
+}
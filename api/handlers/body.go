@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LimitBody ограничивает тело запроса лимитом BodyLimit приложения. При StreamRequestBody Fiber не отклоняет
+// тело больше лимита, а отдает его потоком, и c.Body() или c.BodyParser прочитали бы его в память целиком.
+// Маршруты, для которых streamed возвращает true, читают тело потоком и ограничивают его сами
+func LimitBody(streamed func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Context().RequestBodyStream()
+		if body == nil || streamed(c) {
+			return c.Next()
+		}
+
+		limit := c.App().Config().BodyLimit
+		if c.Request().Header.ContentLength() > limit {
+			// непрочитанный остаток тела не должен попасть в следующий запрос
+			c.Context().SetConnectionClose()
+			return fiber.ErrRequestEntityTooLarge
+		}
+
+		// длина тела может быть неизвестна (chunked), поэтому читаем на байт больше лимита
+		data, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+		if err != nil {
+			c.Context().SetConnectionClose()
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if len(data) > limit {
+			c.Context().SetConnectionClose()
+			return fiber.ErrRequestEntityTooLarge
+		}
+
+		c.Request().SetBodyRaw(data)
+		return c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/ingest"
+	"github.com/orders_api/internal/service"
+)
+
+type BulkHandler struct {
+	service service.ServiceOrder
+	cfg     *ingest.Config
+
+	// свободные места для одновременных импортов
+	slots chan struct{}
+}
+
+func NewBulkHandler(s service.ServiceOrder, cfg *ingest.Config) *BulkHandler {
+	return &BulkHandler{
+		service: s,
+		cfg:     cfg,
+		slots:   make(chan struct{}, max(cfg.MaxRequests, 1)),
+	}
+}
+
+// ImportOrders godoc
+// @Summary Массовый импорт заказов
+// @Description Читает из тела поток заказов в формате NDJSON (заказ на строку) или JSON массив и сохраняет их пачками.
+// @Description Тело не загружается в память целиком. Отчет содержит результат по каждой строке: accepted, duplicate, invalid с ошибками полей или failed.
+// @Description Импорт только создает заказы: заказ с version или updated_at получает invalid, как и в POST /orders.
+// @Description Если тело превысило BULK_MAX_BYTES (413) или JSON массив поврежден (400), отчет содержит строки, обработанные до этого
+// @Tags orders
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param orders body []models.Order true "Заказы: NDJSON или JSON массив"
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {object} models.ImportReport
+// @Failure 413 {object} models.ImportReport
+// @Failure 429 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders:bulk [post]
+func (h *BulkHandler) ImportOrders(c *fiber.Ctx) error {
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		slog.Warn("too many concurrent order imports", "max_requests", h.cfg.MaxRequests)
+		return errs.Send(c, errs.ErrBulkBusy)
+	}
+
+	// при StreamRequestBody тело читается из соединения по мере разбора
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	dec, err := ingest.NewDecoder(ingest.LimitReader(body, h.cfg.MaxBytes))
+	if err != nil {
+		slog.Error("failed to read import body", "error", err)
+		return errs.Send(c, errs.ErrInternalServer)
+	}
+
+	report, err := h.service.ImportOrders(c.UserContext(), dec, h.cfg.BatchSize, h.cfg.Workers, h.cfg.ReportMaxLines)
+	if err != nil {
+		// тело дочитано не до конца: после ответа соединение закрывается, иначе остаток примут за следующий запрос
+		c.Context().SetConnectionClose()
+
+		switch {
+		case errors.Is(err, ingest.ErrBodyTooLarge):
+			slog.Error("import body is too large", "max_bytes", h.cfg.MaxBytes, "lines", report.Total)
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(report)
+
+		case errors.Is(err, ingest.ErrInvalidJSON):
+			slog.Error("invalid import body", "lines", report.Total, "error", err)
+			return c.Status(fiber.StatusBadRequest).JSON(report)
+
+		default:
+			slog.Error("error while importing orders",
+				"lines", report.Total,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	slog.Info("success imported orders",
+		"total", report.Total,
+		"accepted", report.Accepted,
+		"duplicates", report.Duplicates,
+		"invalid", report.Invalid,
+		"failed", report.Failed)
+	return c.Status(fiber.StatusOK).JSON(report)
+}
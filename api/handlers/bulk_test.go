@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/ingest"
+	"github.com/orders_api/internal/models"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ImportOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	bulkHandler := NewBulkHandler(mockService, &ingest.Config{MaxBytes: 1 << 10, BatchSize: 10, Workers: 2, MaxRequests: 1, ReportMaxLines: 100})
+
+	app := fiber.New(fiber.Config{StreamRequestBody: true, BodyLimit: 64})
+	app.Post("/orders\\:bulk", bulkHandler.ImportOrders)
+
+	// сервис читает тело через декодер - разберем его так же, как настоящий сервис
+	importOrders := func(_ any, dec ingest.Decoder, batchSize, workers, maxLines int) (*models.ImportReport, error) {
+		assert.Equal(t, 10, batchSize)
+		assert.Equal(t, 2, workers)
+		assert.Equal(t, 100, maxLines)
+
+		report := &models.ImportReport{}
+		for {
+			line, order, err := dec.Next()
+			if err == io.EOF {
+				return report, nil
+			}
+			if err != nil {
+				report.Error = err.Error()
+				return report, err
+			}
+			report.Count(models.ImportAccepted)
+			report.Lines = append(report.Lines, models.ImportLine{Line: line, Status: models.ImportAccepted, OrderUID: order.OrderUID.String()})
+		}
+	}
+
+	tests := []struct {
+		Name           string
+		Body           string
+		ExpectedStatus int
+		ExpectedTotal  int
+	}{
+		{
+			Name:           "Success_NDJSON_streamed",
+			Body:           strings.Repeat(`{"order_uid": "f47ac10b-58cc-4372-a567-0e02b2c3d479"}`+"\n", 10),
+			ExpectedStatus: http.StatusOK,
+			ExpectedTotal:  10,
+		},
+		{
+			Name:           "Success_array",
+			Body:           `[{"order_uid": "f47ac10b-58cc-4372-a567-0e02b2c3d479"}]`,
+			ExpectedStatus: http.StatusOK,
+			ExpectedTotal:  1,
+		},
+		{
+			Name:           "Error_broken_array",
+			Body:           `[{"order_uid": "f47ac10b-58cc-4372-a567-0e02b2c3d479"}, {`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedTotal:  1,
+		},
+		{
+			Name:           "Error_too_large",
+			Body:           strings.Repeat(`{"order_uid": "f47ac10b-58cc-4372-a567-0e02b2c3d479"}`+"\n", 30),
+			ExpectedStatus: http.StatusRequestEntityTooLarge,
+			ExpectedTotal:  18,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockService.EXPECT().ImportOrders(gomock.Any(), gomock.Any(), 10, 2, 100).DoAndReturn(importOrders)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders:bulk", strings.NewReader(tc.Body))
+			req.Header.Set(fiber.HeaderContentType, "application/x-ndjson")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedStatus, resp.StatusCode)
+
+			var report models.ImportReport
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+			assert.Equal(t, tc.ExpectedTotal, report.Total)
+		})
+	}
+}
+
+func TestHandler_ImportOrdersBusy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bulkHandler := NewBulkHandler(mock_service.NewMockServiceOrder(ctrl), &ingest.Config{MaxRequests: 1})
+	bulkHandler.slots <- struct{}{}
+
+	app := fiber.New()
+	app.Post("/orders\\:bulk", bulkHandler.ImportOrders)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/orders:bulk", strings.NewReader("")))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
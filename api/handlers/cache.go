@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetCacheStats godoc
+// @Summary Статистика кэша заказов
+// @Description Возвращает счетчики попаданий, промахов и вытеснений кэша заказов и его текущий размер
+// @Tags cache
+// @Produce json
+// @Success 200 {object} cache.Stats
+// @Router /cache/stats [get]
+func (h *OrderHandler) GetCacheStats(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.service.CacheStats())
+}
+
+// GetDBStats godoc
+// @Summary Статистика соединений с БД
+// @Description Возвращает число открытых, свободных и занятых соединений пулов primary и реплик, доступность и отставание реплик
+// @Tags db
+// @Produce json
+// @Success 200 {object} postgres.DBStats
+// @Router /db/stats [get]
+func (h *OrderHandler) GetDBStats(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.service.DBStats())
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/orders_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_CreateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	orderHandler := NewOrderHandler(mockService)
+
+	app := fiber.New()
+	app.Post("/orders", orderHandler.CreateOrder)
+
+	orderID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	body := `{"order_uid": "` + orderID + `", "track_number": "WBILMTESTTRACK"}`
+	created := &models.Order{OrderUID: uuid.FromStringOrNil(orderID), TrackNumber: "WBILMTESTTRACK"}
+
+	tests := []struct {
+		Name             string
+		Body             string
+		Key              string
+		ExpectedStatus   int
+		ExpectedBody     string
+		ExpectedLocation string
+		ExpectedReplayed string
+		MockSetup        func(ms *mock_service.MockServiceOrder)
+	}{
+		{
+			Name:           "Error_invalid_JSON",
+			Body:           `{"order_uid": `,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `{"type": "/problems/invalid_json", "title": "Неверный формат данных", "status": 400, "instance": "/orders", "code": "invalid_json"}`,
+			MockSetup:      func(ms *mock_service.MockServiceOrder) {},
+		},
+		{
+			Name:           "Error_validate",
+			Body:           body,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `{"type": "/problems/validation_failed", "title": "Неверно указаны данные", "status": 400, "instance": "/orders", "code": "validation_failed"}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "").Return(nil, false, service.ErrValidateJSON)
+			},
+		},
+		{
+			Name:           "Error_validate_fields",
+			Body:           body,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody: `{"type": "/problems/validation_failed", "title": "Неверно указаны данные", "status": 400, "instance": "/orders", "code": "validation_failed", "fields": [
+				{"field": "items[1].sale", "rule": "lte", "param": "100", "message": "must be less than or equal to 100"}
+			]}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				validateErr := &utils.ValidationError{Fields: []models.FieldError{
+					{Field: "items[1].sale", Rule: "lte", Param: "100", Message: "must be less than or equal to 100"},
+				}}
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "").Return(nil, false, fmt.Errorf("[SetOrder|validate JSON]: %w: %w", service.ErrValidateJSON, validateErr))
+			},
+		},
+		{
+			Name:           "Error_order_exists",
+			Body:           body,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `{"type": "/problems/order_exists", "title": "заказ с таким uuid уже существует", "status": 400, "instance": "/orders", "code": "order_exists"}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "").Return(nil, false, repository.ErrOrderAlreadyExistsUUID)
+			},
+		},
+		{
+			Name:           "Error_track_exists",
+			Body:           body,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `{"type": "/problems/track_number_exists", "title": "заказ с таким трек номером уже существует", "status": 400, "instance": "/orders", "code": "track_number_exists"}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "").Return(nil, false, repository.ErrOrderAlreadyExistsTrack)
+			},
+		},
+		{
+			Name:           "Error_key_reused",
+			Body:           body,
+			Key:            "key-1",
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedBody:   `{"type": "/problems/idempotency_key_reused", "title": "ключ идемпотентности уже использован для другого заказа", "status": 422, "instance": "/orders", "code": "idempotency_key_reused"}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "key-1").Return(nil, false, service.ErrIdempotencyKeyReused)
+			},
+		},
+		{
+			Name:             "Success_created",
+			Body:             body,
+			Key:              "key-1",
+			ExpectedStatus:   http.StatusCreated,
+			ExpectedLocation: "/orders/" + orderID,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "key-1").
+					DoAndReturn(func(_ any, order *models.Order, _ string) (*models.Order, bool, error) {
+						assert.Equal(t, created.OrderUID, order.OrderUID)
+						return created, false, nil
+					})
+			},
+		},
+		{
+			Name:             "Success_replayed",
+			Body:             body,
+			Key:              "key-1",
+			ExpectedStatus:   http.StatusCreated,
+			ExpectedLocation: "/orders/" + orderID,
+			ExpectedReplayed: "true",
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), "key-1").Return(created, true, nil)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.MockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tc.Body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if tc.Key != "" {
+				req.Header.Set(HeaderIdempotencyKey, tc.Key)
+			}
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ExpectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.ExpectedLocation, resp.Header.Get(fiber.HeaderLocation))
+			assert.Equal(t, tc.ExpectedReplayed, resp.Header.Get(HeaderIdempotentReplayed))
+
+			respBody, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tc.ExpectedStatus == http.StatusCreated {
+				assert.Contains(t, string(respBody), `"order_uid":"`+orderID+`"`)
+				return
+			}
+			assert.JSONEq(t, tc.ExpectedBody, string(respBody))
+		})
+	}
+}
+
+func TestHandler_CreateOrderBodyLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	orderHandler := NewOrderHandler(mockService)
+
+	// как в приложении: тела читаются потоком, лимит проверяет LimitBody
+	app := fiber.New(fiber.Config{StreamRequestBody: true, BodyLimit: 1 << 10, ErrorHandler: errs.ErrorHandler})
+	app.Use(LimitBody(func(c *fiber.Ctx) bool { return false }))
+	app.Post("/orders", orderHandler.CreateOrder)
+
+	orderID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	oversized := `{"order_uid": "` + orderID + `", "entry": "` + strings.Repeat("x", 10<<20) + `"}`
+
+	tests := []struct {
+		Name           string
+		Body           string
+		Chunked        bool
+		ExpectedStatus int
+		MockSetup      func(ms *mock_service.MockServiceOrder)
+	}{
+		{
+			Name:           "Error_content_length_over_limit",
+			Body:           oversized,
+			ExpectedStatus: http.StatusRequestEntityTooLarge,
+			MockSetup:      func(ms *mock_service.MockServiceOrder) {},
+		},
+		{
+			Name:           "Error_chunked_over_limit",
+			Body:           oversized,
+			Chunked:        true,
+			ExpectedStatus: http.StatusRequestEntityTooLarge,
+			MockSetup:      func(ms *mock_service.MockServiceOrder) {},
+		},
+		{
+			Name:           "Success_within_limit",
+			Body:           `{"order_uid": "` + orderID + `"}`,
+			ExpectedStatus: http.StatusCreated,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().CreateOrder(gomock.Any(), &models.Order{OrderUID: uuid.Must(uuid.FromString(orderID))}, "").
+					Return(&models.Order{OrderUID: uuid.Must(uuid.FromString(orderID))}, false, nil)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.MockSetup(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tc.Body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if tc.Chunked {
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+			}
+
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ExpectedStatus, resp.StatusCode)
+		})
+	}
+}
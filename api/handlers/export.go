@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/export"
+	"github.com/orders_api/internal/metrics"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/tracing"
+	"github.com/orders_api/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ExportHandler struct {
+	service service.ServiceOrder
+	cfg     *export.Config
+
+	// свободные места для одновременных выгрузок: каждая держит соединение с БД до конца ответа
+	slots chan struct{}
+}
+
+func NewExportHandler(s service.ServiceOrder, cfg *export.Config) *ExportHandler {
+	return &ExportHandler{
+		service: s,
+		cfg:     cfg,
+		slots:   make(chan struct{}, max(cfg.MaxRequests, 1)),
+	}
+}
+
+// ExportOrders godoc
+// @Summary Выгрузка заказов
+// @Description Выгружает все заказы, подходящие под фильтры списка заказов, потоком по мере чтения из БД.
+// @Description Формат задается параметром format или заголовком Accept, по умолчанию CSV. CSV и Parquet содержат строку на каждую позицию заказа вместе с полями заказа, платежа и доставки, NDJSON - заказ целиком на строку.
+// @Description Параметры limit и cursor не учитываются. Если чтение прервалось после начала ответа, выгрузка обрывается: Parquet файл при этом остается без метаданных
+// @Tags orders
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Param format query string false "Формат выгрузки" Enums(csv, ndjson, parquet)
+// @Param sort query string false "Поле сортировки" Enums(date_created, amount)
+// @Param order query string false "Направление сортировки (по умолчанию desc)" Enums(asc, desc)
+// @Param customer_id query string false "Покупатель"
+// @Param delivery_service query string false "Служба доставки"
+// @Param entry query string false "Entry заказа"
+// @Param locale query string false "Локаль заказа"
+// @Param currency query string false "Валюта платежа"
+// @Param provider query string false "Платежный провайдер"
+// @Param bank query string false "Банк"
+// @Param date_from query string false "Создан не раньше (RFC 3339)"
+// @Param date_to query string false "Создан раньше (RFC 3339)"
+// @Param amount_min query int false "Минимальная сумма платежа"
+// @Param amount_max query int false "Максимальная сумма платежа"
+// @Success 200 {file} file
+// @Failure 400 {object} errs.Problem
+// @Failure 406 {object} errs.Problem
+// @Failure 429 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders/export [get]
+func (h *ExportHandler) ExportOrders(c *fiber.Ctx) error {
+	format, err := exportFormat(c)
+	if err != nil {
+		slog.Error("unsupported order export format", "error", err)
+		return errs.Send(c, errs.ErrExportFormat)
+	}
+
+	var query models.OrderListQuery
+	if err := c.QueryParser(&query); err != nil {
+		slog.Error("invalid order export query", "error", err)
+		return errs.Send(c, errs.ErrInvalidListQuery)
+	}
+
+	run, err := h.service.ExportOrders(&query, h.cfg.BatchSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidateJSON):
+			fields := utils.FieldErrors(err)
+			slog.Error("invalid order export query values", "fields", fields)
+			return errs.Send(c, errs.ErrInvalidListQuery.WithFields(fields))
+
+		default:
+			slog.Error("error while preparing order export", "error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+	default:
+		slog.Warn("too many concurrent order exports", "max_requests", h.cfg.MaxRequests)
+		return errs.Send(c, errs.ErrExportBusy)
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="orders.%s"`, format))
+
+	// тело пишется после выхода из хэндлера, место освобождается по окончании выгрузки.
+	// Спан и метрики запроса к этому моменту уже записаны, поэтому запись тела отслеживается своим спаном и метриками
+	ctx := c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer func() { <-h.slots }()
+
+		start := time.Now()
+		ctx, span := tracing.Tracer().Start(ctx, "ExportHandler.stream", trace.WithAttributes(attribute.String("export.format", string(format))))
+
+		rows, err := writeExport(ctx, run, format, w)
+		span.SetAttributes(attribute.Int("export.orders", rows))
+		tracing.End(span, err)
+		metrics.ObserveExport(string(format), rows, start, err)
+		if err != nil {
+			slog.Error("order export interrupted", "format", format, "orders", rows, "error", err)
+			return
+		}
+		slog.Info("success exported orders", "format", format, "orders", rows)
+	})
+	return nil
+}
+
+// exportFormat формат выгрузки из параметра format, если он задан, иначе из заголовка Accept
+func exportFormat(c *fiber.Ctx) (export.Format, error) {
+	if format := c.Query("format"); format != "" {
+		return export.ParseFormat(format)
+	}
+	return export.ParseMediaType(c.Accepts(export.MediaTypes()...))
+}
+
+// writeExport пишет выгрузку в w, отправляя клиенту каждую порцию заказов. Возвращает число выгруженных заказов
+func writeExport(ctx context.Context, run service.OrderExport, format export.Format, w *bufio.Writer) (int, error) {
+	ew, err := export.NewWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	var exported int
+	err = run(ctx, func(orders []*models.Order) error {
+		if err := ew.Write(orders); err != nil {
+			return err
+		}
+		exported += len(orders)
+		// ошибка отправки означает, что клиент отключился: чтение из БД прекращается
+		return w.Flush()
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	if err := ew.Close(); err != nil {
+		return exported, err
+	}
+	return exported, w.Flush()
+}
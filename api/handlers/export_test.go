@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/export"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/service"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/orders_api/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ExportOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	exportHandler := NewExportHandler(mockService, &export.Config{BatchSize: 2, MaxRequests: 1})
+
+	app := fiber.New()
+	app.Get("/orders/export", exportHandler.ExportOrders)
+
+	// выгрузка отдает заказы двумя порциями
+	run := service.OrderExport(func(_ context.Context, fn func(orders []*models.Order) error) error {
+		for _, uid := range []string{"f47ac10b-58cc-4372-a567-0e02b2c3d479", "f47ac10b-58cc-4372-a567-0e02b2c3d480"} {
+			order := &models.Order{OrderUID: uuid.Must(uuid.FromString(uid)), Items: []models.Item{{Name: "item"}}}
+			if err := fn([]*models.Order{order}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	tests := []struct {
+		Name                string
+		Query               string
+		Accept              string
+		MockBehavior        func()
+		ExpectedStatus      int
+		ExpectedContentType string
+		ExpectedBody        func(t *testing.T, body string)
+	}{
+		{
+			Name: "Success_CSV_by_default",
+			MockBehavior: func() {
+				mockService.EXPECT().ExportOrders(gomock.Any(), 2).Return(run, nil)
+			},
+			ExpectedStatus:      http.StatusOK,
+			ExpectedContentType: "text/csv",
+			ExpectedBody: func(t *testing.T, body string) {
+				lines := strings.Split(strings.TrimSpace(body), "\n")
+				require.Len(t, lines, 3)
+				assert.True(t, strings.HasPrefix(lines[0], "order_uid,"))
+				assert.True(t, strings.HasPrefix(lines[2], "f47ac10b-58cc-4372-a567-0e02b2c3d480,"))
+			},
+		},
+		{
+			Name:   "Success_NDJSON_by_Accept",
+			Query:  "?currency=USD&sort=amount",
+			Accept: "application/json;q=0.5, application/x-ndjson",
+			MockBehavior: func() {
+				mockService.EXPECT().ExportOrders(&models.OrderListQuery{Currency: "USD", Sort: models.SortByAmount}, 2).Return(run, nil)
+			},
+			ExpectedStatus:      http.StatusOK,
+			ExpectedContentType: "application/x-ndjson",
+			ExpectedBody: func(t *testing.T, body string) {
+				assert.Equal(t, 2, strings.Count(body, "\n"))
+				assert.Contains(t, body, `"order_uid":"f47ac10b-58cc-4372-a567-0e02b2c3d479"`)
+			},
+		},
+		{
+			Name:   "Success_Parquet_by_param",
+			Query:  "?format=parquet",
+			Accept: "text/csv",
+			MockBehavior: func() {
+				mockService.EXPECT().ExportOrders(gomock.Any(), 2).Return(run, nil)
+			},
+			ExpectedStatus:      http.StatusOK,
+			ExpectedContentType: "application/vnd.apache.parquet",
+			ExpectedBody: func(t *testing.T, body string) {
+				assert.True(t, strings.HasPrefix(body, "PAR1"))
+				assert.True(t, strings.HasSuffix(body, "PAR1"))
+			},
+		},
+		{
+			Name:           "Error_unsupported_Accept",
+			Accept:         "application/json",
+			MockBehavior:   func() {},
+			ExpectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			Name:           "Error_unsupported_format",
+			Query:          "?format=xml",
+			MockBehavior:   func() {},
+			ExpectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			Name:  "Error_invalid_filters",
+			Query: "?date_from=yesterday",
+			MockBehavior: func() {
+				mockService.EXPECT().ExportOrders(gomock.Any(), 2).Return(nil, fmt.Errorf("[ListOrders|validate query]: %w", service.ErrValidateJSON))
+			},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.MockBehavior()
+
+			req := httptest.NewRequest(fiber.MethodGet, "/orders/export"+test.Query, nil)
+			if test.Accept != "" {
+				req.Header.Set(fiber.HeaderAccept, test.Accept)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.ExpectedStatus, resp.StatusCode)
+			if test.ExpectedContentType == "" {
+				return
+			}
+			assert.Equal(t, test.ExpectedContentType, resp.Header.Get(fiber.HeaderContentType))
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			test.ExpectedBody(t, string(body))
+		})
+	}
+}
+
+func TestHandler_ExportOrdersStreamSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewProvider(&tracing.Config{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	exportHandler := NewExportHandler(mockService, &export.Config{BatchSize: 2, MaxRequests: 1})
+
+	app := fiber.New()
+	app.Use(tracing.HTTPMiddleware())
+	app.Get("/orders/export", exportHandler.ExportOrders)
+
+	// чтение из БД обрывается после первой порции, когда ответ уже начат
+	readErr := errors.New("connection reset")
+	mockService.EXPECT().ExportOrders(gomock.Any(), 2).Return(service.OrderExport(func(_ context.Context, fn func(orders []*models.Order) error) error {
+		order := &models.Order{OrderUID: uuid.Must(uuid.FromString("f47ac10b-58cc-4372-a567-0e02b2c3d479"))}
+		if err := fn([]*models.Order{order}); err != nil {
+			return err
+		}
+		return readErr
+	}), nil)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/orders/export?format=ndjson", nil))
+	require.NoError(t, err)
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// спан записи тела закрывается после спана запроса, когда выгрузка закончена
+	var stream tracetest.SpanStub
+	require.Eventually(t, func() bool {
+		for _, span := range exporter.GetSpans() {
+			if span.Name == "ExportHandler.stream" {
+				stream = span
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// первым закрылся спан запроса: он родитель спана записи тела
+	request := exporter.GetSpans()[0]
+	assert.NotEqual(t, stream.Name, request.Name)
+	assert.Equal(t, request.SpanContext.SpanID(), stream.Parent.SpanID())
+	assert.Equal(t, codes.Error, stream.Status.Code)
+	assert.Contains(t, stream.Attributes, attribute.String("export.format", "ndjson"))
+	assert.Contains(t, stream.Attributes, attribute.Int("export.orders", 1))
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/health"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Liveness godoc
+// @Summary Проверка, что процесс жив
+// @Description Отвечает 200, пока процесс сервиса обрабатывает запросы. Зависимости не проверяются
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(health.Report{Status: health.StatusOK})
+}
+
+// Readiness godoc
+// @Summary Готовность сервиса принимать трафик
+// @Description Проверяет Postgres, брокер Kafka и членство консьюмера в группе, завершение прогрева кэша и версию миграций.
+// @Description Возвращает статус и время каждой проверки; 503, если хотя бы одна не прошла
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	report := h.checker.Run(c.UserContext())
+	if !report.OK() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.Status(fiber.StatusOK).JSON(report)
+}
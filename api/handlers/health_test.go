@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Readiness(t *testing.T) {
+	warmedUp := &health.Flag{}
+	checker := health.NewChecker(50 * time.Millisecond)
+	checker.Add("postgres", func(context.Context) error { return nil })
+	checker.Add("kafka", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	checker.Add("cache_warmup", warmedUp.Check)
+
+	healthHandler := NewHealthHandler(checker)
+	app := fiber.New()
+	app.Get("/healthz", healthHandler.Liveness)
+	app.Get("/readyz", healthHandler.Readiness)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var report health.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, health.StatusOK, report.Checks["postgres"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["kafka"].Error)
+	assert.GreaterOrEqual(t, report.Checks["kafka"].LatencyMs, 50.0)
+	assert.Equal(t, health.ErrNotReady.Error(), report.Checks["cache_warmup"].Error)
+}
+
+func TestHandler_ReadinessOK(t *testing.T) {
+	warmedUp := &health.Flag{}
+	warmedUp.Done()
+	checker := health.NewChecker(time.Second)
+	checker.Add("cache_warmup", warmedUp.Check)
+	checker.Add("migrations", func(context.Context) error { return nil })
+
+	app := fiber.New()
+	app.Get("/readyz", NewHealthHandler(checker).Readiness)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var report health.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.True(t, report.OK())
+	assert.Len(t, report.Checks, 2)
+}
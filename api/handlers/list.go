@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/utils"
+)
+
+// ListOrders godoc
+// @Summary Список заказов
+// @Description Возвращает страницу заказов с фильтрами и сортировкой. Следующая страница запрашивается с курсором next_cursor и теми же параметрами
+// @Tags orders
+// @Produce json
+// @Param limit query int false "Размер страницы (1-100, по умолчанию 20)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param sort query string false "Поле сортировки" Enums(date_created, amount)
+// @Param order query string false "Направление сортировки (по умолчанию desc)" Enums(asc, desc)
+// @Param customer_id query string false "Покупатель"
+// @Param delivery_service query string false "Служба доставки"
+// @Param entry query string false "Entry заказа"
+// @Param locale query string false "Локаль заказа"
+// @Param currency query string false "Валюта платежа"
+// @Param provider query string false "Платежный провайдер"
+// @Param bank query string false "Банк"
+// @Param date_from query string false "Создан не раньше (RFC 3339)"
+// @Param date_to query string false "Создан раньше (RFC 3339)"
+// @Param amount_min query int false "Минимальная сумма платежа"
+// @Param amount_max query int false "Максимальная сумма платежа"
+// @Success 200 {object} models.OrderPage
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders [get]
+func (h *OrderHandler) ListOrders(c *fiber.Ctx) error {
+	var query models.OrderListQuery
+	if err := c.QueryParser(&query); err != nil {
+		slog.Error("invalid order list query", "error", err)
+		return errs.Send(c, errs.ErrInvalidListQuery)
+	}
+
+	page, err := h.service.ListOrders(c.UserContext(), &query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidateJSON):
+			fields := utils.FieldErrors(err)
+			slog.Error("invalid order list query values", "fields", fields)
+			return errs.Send(c, errs.ErrInvalidListQuery.WithFields(fields))
+
+		case errors.Is(err, service.ErrInvalidCursor):
+			slog.Error("invalid order list cursor", "cursor", query.Cursor)
+			return errs.Send(c, errs.ErrInvalidCursor)
+
+		default:
+			slog.Error("error while listing orders", "error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	slog.Info("success listed orders", "orders", len(page.Orders))
+	return c.Status(fiber.StatusOK).JSON(page)
+}
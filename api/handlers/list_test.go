@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/service"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	orderHandler := NewOrderHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/orders", orderHandler.ListOrders)
+
+	amountMin := 100
+
+	tests := []struct {
+		Name           string
+		Query          string
+		ExpectedStatus int
+		ExpectedBody   string
+		MockSetup      func(ms *mock_service.MockServiceOrder)
+	}{
+		{
+			Name:           "Error_unparsable_query",
+			Query:          "?limit=ten",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody: `{
+		"type":     "/problems/invalid_list_query",
+		"title":    "Неверно указаны параметры списка заказов",
+		"status":   400,
+		"instance": "/orders",
+		"code":     "invalid_list_query"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {},
+		},
+		{
+			Name:           "Error_invalid_cursor",
+			Query:          "?cursor=broken",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody: `{
+		"type":     "/problems/invalid_cursor",
+		"title":    "Неверный курсор страницы",
+		"status":   400,
+		"instance": "/orders",
+		"code":     "invalid_cursor"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().ListOrders(gomock.Any(), &models.OrderListQuery{Cursor: "broken"}).
+					Return(nil, service.ErrInvalidCursor)
+			},
+		},
+		{
+			Name:           "Success_filtered_page",
+			Query:          "?limit=1&sort=amount&order=asc&currency=RUB&amount_min=100",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody: `{
+		"orders": [],
+		"next_cursor": "next"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().ListOrders(gomock.Any(), &models.OrderListQuery{
+					Limit:     1,
+					Sort:      models.SortByAmount,
+					Order:     models.SortAsc,
+					Currency:  "RUB",
+					AmountMin: &amountMin,
+				}).Return(&models.OrderPage{Orders: []*models.Order{}, NextCursor: "next"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/orders"+tt.Query, nil)
+
+			tt.MockSetup(mockService)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.JSONEq(t, tt.ExpectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/utils"
+)
+
+// GetOrderByTrack godoc
+// @Summary Поиск заказа по трек номеру
+// @Description Возвращает заказ по трек номеру, который сообщают покупатели и курьеры
+// @Tags orders
+// @Produce json
+// @Param track_number path string true "Трек номер"
+// @Success 200 {object} models.Order
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders/by-track/{track_number} [get]
+func (h *OrderHandler) GetOrderByTrack(c *fiber.Ctx) error {
+	track := c.Params("track_number")
+
+	respOrder, err := h.service.GetOrderByTrack(c.UserContext(), track)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrOrderNotFoundByTrack):
+			slog.Error("order not found with track_number", "track_number", track)
+			return errs.Send(c, errs.ErrOrderNotFound)
+
+		default:
+			slog.Error("error while finding order",
+				"track_number", track,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	slog.Info("success found order with track_number",
+		"track_number", track)
+	return c.Status(fiber.StatusOK).JSON(respOrder)
+}
+
+// GetOrderByTransaction godoc
+// @Summary Поиск заказа по транзакции платежа
+// @Description Возвращает заказ по идентификатору транзакции его платежа
+// @Tags orders
+// @Produce json
+// @Param transaction path string true "Транзакция платежа" Format(uuid)
+// @Success 200 {object} models.Order
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders/by-transaction/{transaction} [get]
+func (h *OrderHandler) GetOrderByTransaction(c *fiber.Ctx) error {
+	transaction := c.Params("transaction")
+
+	respOrder, err := h.service.GetOrderByTransaction(c.UserContext(), transaction)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidUUID):
+			slog.Error("invalid transaction format", "transaction", transaction)
+			return errs.Send(c, errs.ErrInvalidUUID)
+
+		case errors.Is(err, repository.ErrOrderNotFoundByTransaction):
+			slog.Error("order not found with transaction", "transaction", transaction)
+			return errs.Send(c, errs.ErrOrderNotFound)
+
+		default:
+			slog.Error("error while finding order",
+				"transaction", transaction,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	slog.Info("success found order with transaction",
+		"transaction", transaction)
+	return c.Status(fiber.StatusOK).JSON(respOrder)
+}
+
+// GetCustomerOrders godoc
+// @Summary Заказы покупателя
+// @Description Возвращает страницу заказов покупателя, новые первыми. Следующая страница запрашивается с курсором next_cursor. У покупателя без заказов список пустой
+// @Tags orders
+// @Produce json
+// @Param customer_id path string true "Покупатель"
+// @Param limit query int false "Размер страницы (1-100, по умолчанию 20)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} models.OrderPage
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /customers/{customer_id}/orders [get]
+func (h *OrderHandler) GetCustomerOrders(c *fiber.Ctx) error {
+	customerID := c.Params("customer_id")
+
+	var query models.CustomerOrdersQuery
+	if err := c.QueryParser(&query); err != nil {
+		slog.Error("invalid customer orders query", "customer_id", customerID, "error", err)
+		return errs.Send(c, errs.ErrInvalidListQuery)
+	}
+
+	page, err := h.service.GetCustomerOrders(c.UserContext(), customerID, &query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidateJSON):
+			fields := utils.FieldErrors(err)
+			slog.Error("invalid customer orders query values", "customer_id", customerID, "fields", fields)
+			return errs.Send(c, errs.ErrInvalidListQuery.WithFields(fields))
+
+		case errors.Is(err, service.ErrInvalidCursor):
+			slog.Error("invalid customer orders cursor", "customer_id", customerID, "cursor", query.Cursor)
+			return errs.Send(c, errs.ErrInvalidCursor)
+
+		default:
+			slog.Error("error while finding customer orders",
+				"customer_id", customerID,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	slog.Info("success found customer orders",
+		"customer_id", customerID,
+		"orders", len(page.Orders))
+	return c.Status(fiber.StatusOK).JSON(page)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Lookups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	orderHandler := NewOrderHandler(mockService)
+
+	app := fiber.New()
+	app.Get("/orders/by-track/:track_number", orderHandler.GetOrderByTrack)
+	app.Get("/orders/by-transaction/:transaction", orderHandler.GetOrderByTransaction)
+	app.Get("/customers/:customer_id/orders", orderHandler.GetCustomerOrders)
+
+	tests := []struct {
+		Name           string
+		Path           string
+		ExpectedStatus int
+		ExpectedBody   string
+		MockSetup      func(ms *mock_service.MockServiceOrder)
+	}{
+		{
+			Name:           "Error_track_not_found",
+			Path:           "/orders/by-track/WBILMTESTTRACK",
+			ExpectedStatus: http.StatusNotFound,
+			ExpectedBody: `{
+		"type":     "/problems/order_not_found",
+		"title":    "заказ не найден",
+		"status":   404,
+		"instance": "/orders/by-track/WBILMTESTTRACK",
+		"code":     "order_not_found"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().GetOrderByTrack(gomock.Any(), "WBILMTESTTRACK").Return(nil, repository.ErrOrderNotFoundByTrack)
+			},
+		},
+		{
+			Name:           "Error_wrong_transaction",
+			Path:           "/orders/by-transaction/wrong_uuid",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody: `{
+		"type":     "/problems/invalid_uuid",
+		"title":    "Неверный формат uuid",
+		"status":   400,
+		"instance": "/orders/by-transaction/wrong_uuid",
+		"code":     "invalid_uuid"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().GetOrderByTransaction(gomock.Any(), "wrong_uuid").Return(nil, service.ErrInvalidUUID)
+			},
+		},
+		{
+			Name:           "Success_customer_without_orders",
+			Path:           "/customers/test/orders",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   `{"orders": []}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().GetCustomerOrders(gomock.Any(), "test", &models.CustomerOrdersQuery{}).
+					Return(&models.OrderPage{Orders: []*models.Order{}}, nil)
+			},
+		},
+		{
+			Name:           "Error_customer_orders_cursor",
+			Path:           "/customers/test/orders?limit=10&cursor=wrong",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody: `{
+		"type":     "/problems/invalid_cursor",
+		"title":    "Неверный курсор страницы",
+		"status":   400,
+		"instance": "/customers/test/orders",
+		"code":     "invalid_cursor"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().GetCustomerOrders(gomock.Any(), "test", &models.CustomerOrdersQuery{Limit: 10, Cursor: "wrong"}).
+					Return(nil, service.ErrInvalidCursor)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.Path, nil)
+
+			tt.MockSetup(mockService)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.JSONEq(t, tt.ExpectedBody, string(body))
+		})
+	}
+}
@@ -6,8 +6,16 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/models"
 	"github.com/orders_api/internal/repository"
 	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/utils"
+)
+
+// заголовки идемпотентного создания заказа
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
 )
 
 type OrderHandler struct {
@@ -28,29 +36,29 @@ func NewOrderHandler(s service.ServiceOrder) *OrderHandler {
 // @Produce json
 // @Param order_uid path string true "Order UUID" Format(uuid)
 // @Success 200 {object} models.Order
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 404 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
 // @Router /orders/{id} [get]
 func (h *OrderHandler) GetOrderByUID(c *fiber.Ctx) error {
 	order_id := c.Params("order_uid")
 
-	respOrder, err := h.service.GetOrderByUID(order_id)
+	respOrder, err := h.service.GetOrderByUID(c.UserContext(), order_id)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrInvalidUUID):
 			slog.Error("invalid order_uuid format", "order_uuid", order_id)
-			return c.Status(errs.ErrInvalidUUID.Code).JSON(errs.ErrInvalidUUID)
+			return errs.Send(c, errs.ErrInvalidUUID)
 
 		case errors.Is(err, repository.ErrOrderNotFoundByUUID):
 			slog.Error("order not found with order_uuid", "order_uuid", order_id)
-			return c.Status(errs.ErrOrderNotFound.Code).JSON(errs.ErrOrderNotFound)
+			return errs.Send(c, errs.ErrOrderNotFound)
 
 		default:
 			slog.Error("error while finding order",
 				"order_uuid", order_id,
 				"error", err)
-			return c.Status(errs.ErrInternalServer.Code).JSON(errs.ErrInternalServer)
+			return errs.Send(c, errs.ErrInternalServer)
 		}
 	}
 
@@ -59,3 +67,71 @@ func (h *OrderHandler) GetOrderByUID(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(respOrder)
 
 }
+
+// CreateOrder godoc
+// @Summary Создание заказа
+// @Description Сохраняет заказ так же, как заказы из Kafka. Повтор запроса с тем же заголовком Idempotency-Key
+// @Description возвращает уже созданный заказ (заголовок Idempotent-Replayed: true), с другим заказом - 422.
+// @Description Заказ с version или updated_at отклоняется (400): заменять сохраненные заказы можно только через Kafka
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Ключ идемпотентности запроса"
+// @Param order body models.Order true "Заказ"
+// @Success 201 {object} models.Order
+// @Header 201 {string} Location "/orders/{order_uid}"
+// @Failure 400 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders [post]
+func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
+	key := c.Get(HeaderIdempotencyKey)
+
+	var order models.Order
+	if err := c.BodyParser(&order); err != nil {
+		slog.Error("invalid order body", "error", err)
+		return errs.Send(c, errs.ErrInvalidJSON)
+	}
+
+	newOrder, replayed, err := h.service.CreateOrder(c.UserContext(), &order, key)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidateJSON):
+			fields := utils.FieldErrors(err)
+			slog.Error("invalid order values", "order_uuid", order.OrderUID, "fields", fields)
+			return errs.Send(c, errs.ErrValidateJSON.WithFields(fields))
+
+		case errors.Is(err, repository.ErrOrderAlreadyExistsUUID):
+			slog.Error("order with order_uuid already exists", "order_uuid", order.OrderUID)
+			return errs.Send(c, errs.ErrOrderExistsUUID)
+
+		case errors.Is(err, repository.ErrOrderAlreadyExistsTrack):
+			slog.Error("order with track_number already exists", "order_uuid", order.OrderUID, "track_number", order.TrackNumber)
+			return errs.Send(c, errs.ErrOrderExistsTrack)
+
+		case errors.Is(err, service.ErrInvalidStatusTransition):
+			slog.Error("invalid order status transition", "order_uuid", order.OrderUID, "error", err)
+			return errs.Send(c, errs.ErrInvalidStatusTransition)
+
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			slog.Error("idempotency key reused with another order", "order_uuid", order.OrderUID, "idempotency_key", key)
+			return errs.Send(c, errs.ErrIdempotencyKeyReused)
+
+		default:
+			slog.Error("error while creating order",
+				"order_uuid", order.OrderUID,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	if replayed {
+		c.Set(HeaderIdempotentReplayed, "true")
+	}
+	c.Location("/orders/" + newOrder.OrderUID.String())
+
+	slog.Info("success created order with order_uuid",
+		"order_uuid", newOrder.OrderUID,
+		"replayed", replayed)
+	return c.Status(fiber.StatusCreated).JSON(newOrder)
+}
@@ -40,11 +40,14 @@ func TestHandler_GetOrder(t *testing.T) {
 			ID:             "wrong_uuid",
 			ExpectedStatus: http.StatusBadRequest,
 			ExpectedBody: `{
-		"code": 400,
-		"msg":  "Неверный формат uuid"
+		"type":     "/problems/invalid_uuid",
+		"title":    "Неверный формат uuid",
+		"status":   400,
+		"instance": "/orders/wrong_uuid",
+		"code":     "invalid_uuid"
 	}`,
 			MockSetup: func(ms *mock_service.MockServiceOrder) {
-				ms.EXPECT().GetOrderByUID("wrong_uuid").Return(nil, service.ErrInvalidUUID)
+				ms.EXPECT().GetOrderByUID(gomock.Any(), "wrong_uuid").Return(nil, service.ErrInvalidUUID)
 			},
 		},
 		{
@@ -52,11 +55,14 @@ func TestHandler_GetOrder(t *testing.T) {
 			ID:             "f47ac10b-58cc-4372-a567-0e02b2c3d400",
 			ExpectedStatus: http.StatusNotFound,
 			ExpectedBody: `{
-					"code": 404,
-					"msg":  "заказ не найден"
+					"type":     "/problems/order_not_found",
+					"title":    "заказ не найден",
+					"status":   404,
+					"instance": "/orders/f47ac10b-58cc-4372-a567-0e02b2c3d400",
+					"code":     "order_not_found"
 				}`,
 			MockSetup: func(ms *mock_service.MockServiceOrder) {
-				ms.EXPECT().GetOrderByUID("f47ac10b-58cc-4372-a567-0e02b2c3d400").Return(nil, repository.ErrOrderNotFoundByUUID)
+				ms.EXPECT().GetOrderByUID(gomock.Any(), "f47ac10b-58cc-4372-a567-0e02b2c3d400").Return(nil, repository.ErrOrderNotFoundByUUID)
 			},
 		},
 		{
@@ -125,7 +131,7 @@ func TestHandler_GetOrder(t *testing.T) {
 				if err != nil {
 					t.Fatal(err)
 				}
-				ms.EXPECT().GetOrderByUID("f47ac10b-58cc-4372-a567-0e02b2c3d479").
+				ms.EXPECT().GetOrderByUID(gomock.Any(), "f47ac10b-58cc-4372-a567-0e02b2c3d479").
 					Return(
 						&models.Order{
 							OrderUID:    readyUUID,
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/errs"
+)
+
+type ProblemHandler struct{}
+
+func NewProblemHandler() *ProblemHandler {
+	return &ProblemHandler{}
+}
+
+// GetProblemType godoc
+// @Summary Описание типа ошибки
+// @Description Описание ошибки, на которое указывает поле type ответа об ошибке: код, HTTP статус и заголовок на языке из Accept-Language (ru, en)
+// @Tags errors
+// @Produce json
+// @Param code path string true "Код ошибки"
+// @Param Accept-Language header string false "Язык заголовка" Enums(ru, en)
+// @Success 200 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Router /problems/{code} [get]
+func (h *ProblemHandler) GetProblemType(c *fiber.Ctx) error {
+	e, ok := errs.Lookup(c.Params("code"))
+	if !ok {
+		return errs.Send(c, errs.ErrRouteNotFound)
+	}
+
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentLanguage, errs.Language(c))
+	return c.Status(fiber.StatusOK).JSON(errs.NewProblem(c, e))
+}
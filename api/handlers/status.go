@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/utils"
+)
+
+// ChangeOrderStatus godoc
+// @Summary Смена статуса заказа
+// @Description Переводит заказ в новый статус, если переход разрешен, и записывает его в историю статусов
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order_uid path string true "Order UUID" Format(uuid)
+// @Param request body models.StatusChangeRequest true "Новый статус и автор изменения"
+// @Success 200 {object} models.StatusChange
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders/{order_uid}/status [patch]
+func (h *OrderHandler) ChangeOrderStatus(c *fiber.Ctx) error {
+	order_id := c.Params("order_uid")
+
+	var req models.StatusChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.Error("invalid status change body", "order_uuid", order_id, "error", err)
+		return errs.Send(c, errs.ErrInvalidJSON)
+	}
+
+	change, err := h.service.ChangeOrderStatus(c.UserContext(), order_id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidUUID):
+			slog.Error("invalid order_uuid format", "order_uuid", order_id)
+			return errs.Send(c, errs.ErrInvalidUUID)
+
+		case errors.Is(err, service.ErrValidateJSON):
+			fields := utils.FieldErrors(err)
+			slog.Error("invalid status change values", "order_uuid", order_id, "fields", fields)
+			return errs.Send(c, errs.ErrValidateJSON.WithFields(fields))
+
+		case errors.Is(err, service.ErrInvalidStatus):
+			slog.Error("unknown order status", "order_uuid", order_id, "status", req.Status)
+			return errs.Send(c, errs.ErrInvalidStatus)
+
+		case errors.Is(err, repository.ErrOrderNotFoundByUUID):
+			slog.Error("order not found with order_uuid", "order_uuid", order_id)
+			return errs.Send(c, errs.ErrOrderNotFound)
+
+		case errors.Is(err, service.ErrInvalidStatusTransition):
+			slog.Error("invalid order status transition", "order_uuid", order_id, "error", err)
+			return errs.Send(c, errs.ErrInvalidStatusTransition)
+
+		case errors.Is(err, repository.ErrOrderStatusConflict):
+			slog.Error("concurrent order status change", "order_uuid", order_id)
+			return errs.Send(c, errs.ErrStatusConflict)
+
+		default:
+			slog.Error("error while changing order status",
+				"order_uuid", order_id,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	slog.Info("success changed order status",
+		"order_uuid", order_id,
+		"status", change.ToStatus)
+	return c.Status(fiber.StatusOK).JSON(change)
+}
+
+// GetStatusHistory godoc
+// @Summary История статусов заказа
+// @Description Возвращает все смены статуса заказа в хронологическом порядке
+// @Tags orders
+// @Produce json
+// @Param order_uid path string true "Order UUID" Format(uuid)
+// @Success 200 {array} models.StatusChange
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Router /orders/{order_uid}/status/history [get]
+func (h *OrderHandler) GetStatusHistory(c *fiber.Ctx) error {
+	order_id := c.Params("order_uid")
+
+	history, err := h.service.GetStatusHistory(c.UserContext(), order_id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidUUID):
+			slog.Error("invalid order_uuid format", "order_uuid", order_id)
+			return errs.Send(c, errs.ErrInvalidUUID)
+
+		case errors.Is(err, repository.ErrOrderNotFoundByUUID):
+			slog.Error("order not found with order_uuid", "order_uuid", order_id)
+			return errs.Send(c, errs.ErrOrderNotFound)
+
+		default:
+			slog.Error("error while finding order status history",
+				"order_uuid", order_id,
+				"error", err)
+			return errs.Send(c, errs.ErrInternalServer)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(history)
+}
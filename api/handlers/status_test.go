@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ChangeOrderStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockServiceOrder(ctrl)
+	orderHandler := NewOrderHandler(mockService)
+
+	app := fiber.New()
+	app.Patch("/orders/:order_uid/status", orderHandler.ChangeOrderStatus)
+
+	orderID := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+
+	tests := []struct {
+		Name           string
+		Body           string
+		ExpectedStatus int
+		ExpectedBody   string
+		MockSetup      func(ms *mock_service.MockServiceOrder)
+	}{
+		{
+			Name:           "Error_invalid_json",
+			Body:           `{"status":`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody: `{
+		"type":     "/problems/invalid_json",
+		"title":    "Неверный формат данных",
+		"status":   400,
+		"instance": "/orders/f47ac10b-58cc-4372-a567-0e02b2c3d479/status",
+		"code":     "invalid_json"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {},
+		},
+		{
+			Name:           "Error_invalid_transition",
+			Body:           `{"status":"delivered","actor":"courier"}`,
+			ExpectedStatus: http.StatusConflict,
+			ExpectedBody: `{
+		"type":     "/problems/invalid_status_transition",
+		"title":    "недопустимый переход статуса заказа",
+		"status":   409,
+		"instance": "/orders/f47ac10b-58cc-4372-a567-0e02b2c3d479/status",
+		"code":     "invalid_status_transition"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().ChangeOrderStatus(gomock.Any(), orderID, &models.StatusChangeRequest{Status: models.StatusDelivered, Actor: "courier"}).
+					Return(nil, service.ErrInvalidStatusTransition)
+			},
+		},
+		{
+			Name:           "Error_order_not_found",
+			Body:           `{"status":"paid","actor":"billing"}`,
+			ExpectedStatus: http.StatusNotFound,
+			ExpectedBody: `{
+		"type":     "/problems/order_not_found",
+		"title":    "заказ не найден",
+		"status":   404,
+		"instance": "/orders/f47ac10b-58cc-4372-a567-0e02b2c3d479/status",
+		"code":     "order_not_found"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				ms.EXPECT().ChangeOrderStatus(gomock.Any(), orderID, &models.StatusChangeRequest{Status: models.StatusPaid, Actor: "billing"}).
+					Return(nil, repository.ErrOrderNotFoundByUUID)
+			},
+		},
+		{
+			Name:           "Success_changed_status",
+			Body:           `{"status":"paid","actor":"billing"}`,
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody: `{
+		"order_uid": "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"from_status": "created",
+		"to_status": "paid",
+		"actor": "billing",
+		"changed_at": "2021-11-26T06:22:19Z"
+	}`,
+			MockSetup: func(ms *mock_service.MockServiceOrder) {
+				changedAt, err := time.Parse(time.RFC3339, "2021-11-26T06:22:19Z")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ms.EXPECT().ChangeOrderStatus(gomock.Any(), orderID, &models.StatusChangeRequest{Status: models.StatusPaid, Actor: "billing"}).
+					Return(&models.StatusChange{
+						OrderUID:   uuid.Must(uuid.FromString(orderID)),
+						FromStatus: models.StatusCreated,
+						ToStatus:   models.StatusPaid,
+						Actor:      "billing",
+						ChangedAt:  changedAt,
+					}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", fmt.Sprintf("/orders/%s/status", orderID), strings.NewReader(tt.Body))
+			req.Header.Set("Content-Type", "application/json")
+
+			tt.MockSetup(mockService)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.JSONEq(t, tt.ExpectedBody, string(body))
+		})
+	}
+}
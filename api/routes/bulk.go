@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/handlers"
+)
+
+func InitRoutesForBulk(app *fiber.App, handler *handlers.BulkHandler) {
+	// двоеточие в пути экранируется, иначе Fiber считает его параметром
+	app.Post("/orders\\:bulk", handler.ImportOrders)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/handlers"
+)
+
+// InitRoutesForExport регистрируется до роутов заказов, иначе /orders/export совпадет с /orders/:order_uid
+func InitRoutesForExport(app *fiber.App, handler *handlers.ExportHandler) {
+	app.Get("/orders/export", handler.ExportOrders)
+}
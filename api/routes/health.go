@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/handlers"
+)
+
+func InitRoutesForHealth(app *fiber.App, handler *handlers.HealthHandler) {
+	app.Get("/healthz", handler.Liveness)
+	app.Get("/readyz", handler.Readiness)
+}
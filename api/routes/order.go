@@ -9,7 +9,17 @@ import (
 func InitRoutesForOrders(app *fiber.App, handler *handlers.OrderHandler) {
 	api := app.Group("/")
 
+	api.Get("/orders", handler.ListOrders)
+	api.Post("/orders", handler.CreateOrder)
+	api.Get("/orders/by-track/:track_number", handler.GetOrderByTrack)
+	api.Get("/orders/by-transaction/:transaction", handler.GetOrderByTransaction)
+	api.Get("/customers/:customer_id/orders", handler.GetCustomerOrders)
 	api.Get("/orders/:order_uid", handler.GetOrderByUID)
+	api.Patch("/orders/:order_uid/status", handler.ChangeOrderStatus)
+	api.Get("/orders/:order_uid/status/history", handler.GetStatusHistory)
+
+	api.Get("/cache/stats", handler.GetCacheStats)
+	api.Get("/db/stats", handler.GetDBStats)
 }
 
 func InitRouteForSwagger(app *fiber.App) {
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/api/handlers"
+)
+
+func InitRoutesForProblems(app *fiber.App, handler *handlers.ProblemHandler) {
+	app.Get("/problems/:code", handler.GetProblemType)
+}
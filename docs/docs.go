@@ -15,11 +15,721 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/cache/stats": {
+            "get": {
+                "description": "Возвращает счетчики попаданий, промахов и вытеснений кэша заказов и его текущий размер",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Статистика кэша заказов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_database_cache.Stats"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{customer_id}/orders": {
+            "get": {
+                "description": "Возвращает страницу заказов покупателя, новые первыми. Следующая страница запрашивается с курсором next_cursor. У покупателя без заказов список пустой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Заказы покупателя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Покупатель",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.OrderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/db/stats": {
+            "get": {
+                "description": "Возвращает число открытых, свободных и занятых соединений пулов primary и реплик, доступность и отставание реплик",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "db"
+                ],
+                "summary": "Статистика соединений с БД",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_database_postgres.DBStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Отвечает 200, пока процесс сервиса обрабатывает запросы. Зависимости не проверяются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка, что процесс жив",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Возвращает страницу заказов с фильтрами и сортировкой. Следующая страница запрашивается с курсором next_cursor и теми же параметрами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Список заказов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "date_created",
+                            "amount"
+                        ],
+                        "type": "string",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Направление сортировки (по умолчанию desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Покупатель",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Служба доставки",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entry заказа",
+                        "name": "entry",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Локаль заказа",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта платежа",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Платежный провайдер",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Банк",
+                        "name": "bank",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан не раньше (RFC 3339)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан раньше (RFC 3339)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная сумма платежа",
+                        "name": "amount_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная сумма платежа",
+                        "name": "amount_max",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.OrderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Сохраняет заказ так же, как заказы из Kafka. Повтор запроса с тем же заголовком Idempotency-Key\nвозвращает уже созданный заказ (заголовок Idempotent-Replayed: true), с другим заказом - 422.\nЗаказ с version или updated_at отклоняется (400): заменять сохраненные заказы можно только через Kafka",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Создание заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Заказ",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/orders/{order_uid}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/by-track/{track_number}": {
+            "get": {
+                "description": "Возвращает заказ по трек номеру, который сообщают покупатели и курьеры",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Поиск заказа по трек номеру",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Трек номер",
+                        "name": "track_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/by-transaction/{transaction}": {
+            "get": {
+                "description": "Возвращает заказ по идентификатору транзакции его платежа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Поиск заказа по транзакции платежа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Транзакция платежа",
+                        "name": "transaction",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/export": {
+            "get": {
+                "description": "Выгружает все заказы, подходящие под фильтры списка заказов, потоком по мере чтения из БД.\nФормат задается параметром format или заголовком Accept, по умолчанию CSV. CSV и Parquet содержат строку на каждую позицию заказа вместе с полями заказа, платежа и доставки, NDJSON - заказ целиком на строку.\nПараметры limit и cursor не учитываются. Если чтение прервалось после начала ответа, выгрузка обрывается: Parquet файл при этом остается без метаданных",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Выгрузка заказов",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "description": "Формат выгрузки",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "date_created",
+                            "amount"
+                        ],
+                        "type": "string",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Направление сортировки (по умолчанию desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Покупатель",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Служба доставки",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entry заказа",
+                        "name": "entry",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Локаль заказа",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта платежа",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Платежный провайдер",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Банк",
+                        "name": "bank",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан не раньше (RFC 3339)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создан раньше (RFC 3339)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная сумма платежа",
+                        "name": "amount_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная сумма платежа",
+                        "name": "amount_max",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
         "/orders/{id}": {
             "get": {
                 "description": "Регистрирует нового пользователя",
                 "consumes": [
-                    "application/json"
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Order UUID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_uid}/status": {
+            "patch": {
+                "description": "Переводит заказ в новый статус, если переход разрешен, и записывает его в историю статусов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Смена статуса заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Order UUID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый статус и автор изменения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.StatusChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.StatusChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_uid}/status/history": {
+            "get": {
+                "description": "Возвращает все смены статуса заказа в хронологическом порядке",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "История статусов заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Order UUID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_orders_api_internal_models.StatusChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders:bulk": {
+            "post": {
+                "description": "Читает из тела поток заказов в формате NDJSON (заказ на строку) или JSON массив и сохраняет их пачками.\nТело не загружается в память целиком. Отчет содержит результат по каждой строке: accepted, duplicate, invalid с ошибками полей или failed.\nИмпорт только создает заказы: заказ с version или updated_at получает invalid, как и в POST /orders.\nЕсли тело превысило BULK_MAX_BYTES (413) или JSON массив поврежден (400), отчет содержит строки, обработанные до этого",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
                 ],
                 "produces": [
                     "application/json"
@@ -27,40 +737,121 @@ const docTemplate = `{
                 "tags": [
                     "orders"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Массовый импорт заказов",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Order UUID",
-                        "name": "order_uid",
-                        "in": "path",
-                        "required": true
+                        "description": "Заказы: NDJSON или JSON массив",
+                        "name": "orders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.ImportReport"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_orders_api_api_errs.ErrorResponse"
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.ImportReport"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
-                            "$ref": "#/definitions/github_com_orders_api_api_errs.ErrorResponse"
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.ImportReport"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/github_com_orders_api_api_errs.ErrorResponse"
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/problems/{code}": {
+            "get": {
+                "description": "Описание ошибки, на которое указывает поле type ответа об ошибке: код, HTTP статус и заголовок на языке из Accept-Language (ru, en)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "errors"
+                ],
+                "summary": "Описание типа ошибки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код ошибки",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "ru",
+                            "en"
+                        ],
+                        "type": "string",
+                        "description": "Язык заголовка",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_api_errs.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет Postgres, брокер Kafka и членство консьюмера в группе, завершение прогрева кэша и версию миграций.\nВозвращает статус и время каждой проверки; 503, если хотя бы одна не прошла",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Готовность сервиса принимать трафик",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_orders_api_internal_health.Report"
                         }
                     }
                 }
@@ -68,15 +859,100 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "github_com_orders_api_api_errs.ErrorResponse": {
-            "description": "Модель описывает возвращаемую ошибку: код и краткое сообщение",
+        "github_com_orders_api_internal_database_cache.Stats": {
+            "description": "Обращения к кэшу заказов: попадания, промахи, вытеснения, истечения TTL и текущий размер",
             "type": "object",
             "properties": {
-                "code": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "evictions": {
                     "type": "integer"
                 },
-                "msg": {
+                "expirations": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "remote": {
+                    "description": "счетчики общего кэша в tiered режиме",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_orders_api_internal_database_cache.Stats"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_orders_api_api_errs.Problem": {
+            "description": "Тип ошибки (URI ее описания), заголовок на языке из Accept-Language, HTTP статус, путь запроса, стабильный машиночитаемый код и поля запроса, не прошедшие проверку",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "order_not_found"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_orders_api_internal_models.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/orders/f47ac10b-58cc-4372-a567-0e02b2c3d479"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Order not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/problems/order_not_found"
+                }
+            }
+        },
+        "github_com_orders_api_internal_health.Report": {
+            "description": "Общий статус готовности сервиса и результаты проверок по зависимостям",
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/github_com_orders_api_internal_health.Result"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "github_com_orders_api_internal_health.Result": {
+            "description": "Статус проверки зависимости, время ее выполнения и ошибка, если проверка не прошла",
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
                 }
             }
         },
@@ -112,6 +988,122 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_orders_api_internal_models.FieldError": {
+            "description": "Поле в виде JSON пути (например items[1].sale), нарушенное правило, его параметр и описание",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "items[1].sale"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be less than or equal to 100"
+                },
+                "param": {
+                    "type": "string",
+                    "example": "100"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "lte"
+                }
+            }
+        },
+        "github_com_orders_api_internal_models.ImportLine": {
+            "description": "Номер строки, uid заказа, результат и ошибки, если заказ не сохранен. Fields - поля заказа, не прошедшие проверку",
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_orders_api_internal_models.FieldError"
+                    }
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "order_uid": {
+                    "type": "string",
+                    "example": "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.ImportStatus"
+                        }
+                    ],
+                    "example": "accepted"
+                }
+            }
+        },
+        "github_com_orders_api_internal_models.ImportReport": {
+            "description": "Итоги импорта и результат по каждой строке. Error - причина, по которой импорт остановлен до конца тела. В lines не больше BULK_REPORT_MAX_LINES первых строк, остальные (lines_omitted) учтены только в итогах",
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_orders_api_internal_models.ImportLine"
+                    }
+                },
+                "lines_omitted": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_orders_api_internal_models.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "accepted",
+                "duplicate",
+                "invalid",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "ImportAccepted": "заказ сохранен",
+                "ImportDuplicate": "заказ с таким uid, трек номером или транзакцией уже есть",
+                "ImportFailed": "заказ не удалось сохранить, импорт можно повторить",
+                "ImportInvalid": "заказ не разобран или не прошел проверку"
+            },
+            "x-enum-descriptions": [
+                "заказ сохранен",
+                "заказ с таким uid, трек номером или транзакцией уже есть",
+                "заказ не разобран или не прошел проверку",
+                "заказ не удалось сохранить, импорт можно повторить"
+            ],
+            "x-enum-varnames": [
+                "ImportAccepted",
+                "ImportDuplicate",
+                "ImportInvalid",
+                "ImportFailed"
+            ]
+        },
         "github_com_orders_api_internal_models.Item": {
             "description": "Модель описывает информацию о товаре в заказе",
             "type": "object",
@@ -221,11 +1213,71 @@ const docTemplate = `{
                     "type": "integer",
                     "minimum": 0
                 },
+                "status": {
+                    "enum": [
+                        "created",
+                        "paid",
+                        "assembling",
+                        "shipped",
+                        "delivered",
+                        "cancelled",
+                        "returned"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_orders_api_internal_models.OrderStatus"
+                        }
+                    ]
+                },
                 "track_number": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "версия заказа у отправителя: заказ с версией или updated_at заменяет сохраненный, только если он новее",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "github_com_orders_api_internal_models.OrderPage": {
+            "description": "Заказы страницы и курсор следующей страницы; next_cursor отсутствует на последней странице",
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_orders_api_internal_models.Order"
+                    }
                 }
             }
         },
+        "github_com_orders_api_internal_models.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "paid",
+                "assembling",
+                "shipped",
+                "delivered",
+                "cancelled",
+                "returned"
+            ],
+            "x-enum-varnames": [
+                "StatusCreated",
+                "StatusPaid",
+                "StatusAssembling",
+                "StatusShipped",
+                "StatusDelivered",
+                "StatusCancelled",
+                "StatusReturned"
+            ]
+        },
         "github_com_orders_api_internal_models.Payment": {
             "description": "Модель описывает информацию о платеже",
             "type": "object",
@@ -271,6 +1323,123 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "github_com_orders_api_internal_models.StatusChange": {
+            "description": "Модель описывает смену статуса заказа: откуда, куда, кто и когда",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "$ref": "#/definitions/github_com_orders_api_internal_models.OrderStatus"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/github_com_orders_api_internal_models.OrderStatus"
+                }
+            }
+        },
+        "github_com_orders_api_internal_models.StatusChangeRequest": {
+            "description": "Модель описывает новый статус заказа и того, кто его меняет",
+            "type": "object",
+            "required": [
+                "actor",
+                "status"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_orders_api_internal_models.OrderStatus"
+                }
+            }
+        },
+        "github_com_orders_api_internal_database_postgres.DBStats": {
+            "description": "Пул соединений с primary и состояние реплик для чтения",
+            "type": "object",
+            "properties": {
+                "primary": {
+                    "$ref": "#/definitions/github_com_orders_api_internal_database_postgres.PoolStats"
+                },
+                "replicas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_orders_api_internal_database_postgres.ReplicaStats"
+                    }
+                }
+            }
+        },
+        "github_com_orders_api_internal_database_postgres.PoolStats": {
+            "description": "Соединения пула и счетчики их выдачи",
+            "type": "object",
+            "properties": {
+                "acquire_count": {
+                    "type": "integer"
+                },
+                "acquire_duration_ms": {
+                    "description": "суммарное время ожидания соединений",
+                    "type": "integer"
+                },
+                "acquired_conns": {
+                    "type": "integer"
+                },
+                "canceled_acquire_count": {
+                    "type": "integer"
+                },
+                "constructing_conns": {
+                    "type": "integer"
+                },
+                "empty_acquire_count": {
+                    "description": "сколько раз пришлось ждать свободное соединение",
+                    "type": "integer"
+                },
+                "idle_conns": {
+                    "type": "integer"
+                },
+                "max_conns": {
+                    "type": "integer"
+                },
+                "max_idle_destroy_count": {
+                    "type": "integer"
+                },
+                "max_lifetime_destroy_count": {
+                    "type": "integer"
+                },
+                "new_conns_count": {
+                    "type": "integer"
+                },
+                "total_conns": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_orders_api_internal_database_postgres.ReplicaStats": {
+            "description": "Адрес реплики, ее доступность, отставание от primary и пул соединений",
+            "type": "object",
+            "properties": {
+                "addr": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "lag_ms": {
+                    "type": "integer"
+                },
+                "pool": {
+                    "$ref": "#/definitions/github_com_orders_api_internal_database_postgres.PoolStats"
+                }
+            }
         }
     }
 }`
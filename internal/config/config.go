@@ -4,9 +4,15 @@ import (
 	"fmt"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/orders_api/api/errs"
+	"github.com/orders_api/internal/database/cache"
 	"github.com/orders_api/internal/database/postgres"
+	"github.com/orders_api/internal/export"
+	"github.com/orders_api/internal/health"
+	"github.com/orders_api/internal/ingest"
 	"github.com/orders_api/internal/kafka"
 	"github.com/orders_api/internal/logger"
+	"github.com/orders_api/internal/tracing"
 )
 
 type Config struct {
@@ -14,6 +20,12 @@ type Config struct {
 	ServerPort string `env:"SERVER_PORT" envDefault:":3000"`
 	Logger     logger.Config
 	Kafka      kafka.KafkaConfig
+	Cache      cache.CacheConfig
+	Tracing    tracing.Config
+	Health     health.Config
+	Bulk       ingest.Config
+	Export     export.Config
+	Errors     errs.Config
 }
 
 func MustLoad() (*Config, error) {
@@ -1,12 +1,76 @@
 package cache
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/gofrs/uuid"
 	"github.com/orders_api/internal/models"
 )
 
+// причины удаления заказа из кэша
+const (
+	ReasonDeleted = "deleted" // удален явно, например после изменения заказа в БД
+	ReasonExpired = "expired" // истек TTL
+	ReasonEvicted = "evicted" // вытеснен при нехватке места
+)
+
+// InvalidateFunc вызывается после удаления заказа из кэша
+type InvalidateFunc func(id uuid.UUID, reason string)
+
 type Cache interface {
 	Get(id uuid.UUID) (*models.Order, bool)
+	// Set сохраняет заказ с TTL по умолчанию
 	Set(id uuid.UUID, order *models.Order)
+	// SetWithTTL сохраняет заказ на ttl, 0 - без срока хранения
+	SetWithTTL(id uuid.UUID, order *models.Order, ttl time.Duration)
 	SetAll([]*models.Order)
+	Delete(id uuid.UUID)
+	// OnInvalidate регистрирует обработчик удаления заказов из кэша
+	OnInvalidate(fn InvalidateFunc)
+
+	// поиск по вторичным индексам: трек номеру, транзакции платежа и покупателю
+	GetByTrack(track string) (*models.Order, bool)
+	GetByTransaction(transaction uuid.UUID) (*models.Order, bool)
+	GetByCustomer(customerID string) ([]*models.Order, bool)
+	// SetCustomerOrders сохраняет полный список заказов покупателя, после чего GetByCustomer отдает его из кэша
+	SetCustomerOrders(customerID string, orders []*models.Order)
+
+	Stats() Stats
+}
+
+// Background фоновая работа кэша, которую нужно запустить вместе с сервисом
+type Background interface {
+	Run(ctx context.Context)
+}
+
+type warmupLocker interface {
+	tryLockWarmup() bool
+}
+
+// New создает кэш заказов типа cfg.Backend
+func New(cfg *CacheConfig) (Cache, error) {
+	switch cfg.Backend {
+	case BackendLocal, "":
+		return NewOrderCacher(cfg), nil
+
+	case BackendRedis:
+		return NewRedisCache(cfg)
+
+	case BackendTiered:
+		return NewTieredCache(cfg)
+
+	default:
+		return nil, fmt.Errorf("[New|backend]: unknown cache backend %q", cfg.Backend)
+	}
+}
+
+// NeedsWarmup сообщает, нужно ли этой реплике загружать заказы в кэш при старте:
+// общий кэш прогревает только одна реплика
+func NeedsWarmup(c Cache) bool {
+	if l, ok := c.(warmupLocker); ok {
+		return l.tryLockWarmup()
+	}
+	return true
 }
@@ -0,0 +1,37 @@
+package cache
+
+import "time"
+
+// типы кэша заказов
+const (
+	BackendLocal  = "local"  // LRU в памяти процесса
+	BackendRedis  = "redis"  // общий для реплик кэш в Redis
+	BackendTiered = "tiered" // локальный LRU перед общим кэшем в Redis
+)
+
+// CacheConfig ограничения кэша заказов
+type CacheConfig struct {
+	Backend string `env:"CACHE_BACKEND" envDefault:"local"`
+
+	MaxEntries   int   `env:"CACHE_MAX_ENTRIES" envDefault:"10000"`  // 0 - без ограничения
+	MaxBytes     int64 `env:"CACHE_MAX_BYTES" envDefault:"0"`        // примерный объем, 0 - без ограничения
+	WarmupOrders int   `env:"CACHE_WARMUP_ORDERS" envDefault:"1000"` // сколько последних заказов загрузить при старте
+
+	TTL             time.Duration `env:"CACHE_TTL" envDefault:"30m"`             // срок хранения заказа, 0 - без срока
+	LocalTTL        time.Duration `env:"CACHE_LOCAL_TTL" envDefault:"1m"`        // срок хранения в локальном уровне tiered кэша
+	JanitorInterval time.Duration `env:"CACHE_JANITOR_INTERVAL" envDefault:"1m"` // как часто удалять истекшие заказы
+	NotFoundTTL     time.Duration `env:"CACHE_NOT_FOUND_TTL" envDefault:"5s"`    // сколько помнить, что заказа нет в БД, 0 - не запоминать
+
+	SnapshotPath     string        `env:"CACHE_SNAPSHOT_PATH" envDefault:"snapshot/orders_cache.snap"` // пустое значение - без снапшотов
+	SnapshotInterval time.Duration `env:"CACHE_SNAPSHOT_INTERVAL" envDefault:"5m"`                     // как часто сохранять снапшот, 0 - только при остановке
+
+	Redis RedisConfig
+}
+
+type RedisConfig struct {
+	Addr     string        `env:"REDIS_ADDR" envDefault:"redis:6379"`
+	Password string        `env:"REDIS_PASSWORD"`
+	DB       int           `env:"REDIS_DB" envDefault:"0"`
+	Prefix   string        `env:"REDIS_PREFIX" envDefault:"orders_api"` // пространство имен ключей сервиса
+	Timeout  time.Duration `env:"REDIS_TIMEOUT" envDefault:"200ms"`     // таймаут одной операции с кэшем
+}
@@ -1,40 +1,346 @@
 package cache
 
 import (
+	"container/list"
+	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/orders_api/internal/models"
 )
 
+// Stats счетчики кэша заказов
+// @Description Обращения к кэшу заказов: попадания, промахи, вытеснения, истечения TTL и текущий размер
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+	Remote      *Stats `json:"remote,omitempty"` // счетчики общего кэша в tiered режиме
+}
+
+// OrderCacher LRU кэш заказов, ограниченный числом записей и примерным объемом памяти.
+// Заказы хранятся не дольше TTL, истекшие удаляются при обращении и фоновым janitor
 type OrderCacher struct {
-	store map[uuid.UUID]*models.Order
-	mu    sync.RWMutex
+	mu    sync.Mutex
+	store map[uuid.UUID]*list.Element
+	lru   *list.List // от недавно использованных к давно использованным
+
+	maxEntries int
+	maxBytes   int64
+	ttl        time.Duration
+	janitor    time.Duration
+	bytes      int64
+	stats      Stats
+	now        func() time.Time
+
+	// вторичные индексы по закэшированным заказам
+	byTrack       map[string]uuid.UUID
+	byTransaction map[uuid.UUID]uuid.UUID
+	byCustomer    map[string]map[uuid.UUID]struct{}
+	// покупатели, все заказы которых есть в кэше
+	fullCustomers map[string]struct{}
+
+	hooks []InvalidateFunc
+	// удаленные под c.mu заказы, о которых нужно сообщить hooks после снятия блокировки
+	removed []removal
 }
 
-func NewOrderCacher() *OrderCacher {
+type entry struct {
+	id        uuid.UUID
+	order     *models.Order
+	size      int64
+	expiresAt time.Time // нулевое значение - без срока хранения
+}
+
+type removal struct {
+	id     uuid.UUID
+	reason string
+}
+
+func NewOrderCacher(cfg *CacheConfig) *OrderCacher {
 	return &OrderCacher{
-		store: make(map[uuid.UUID]*models.Order),
+		store:         make(map[uuid.UUID]*list.Element),
+		lru:           list.New(),
+		maxEntries:    cfg.MaxEntries,
+		maxBytes:      cfg.MaxBytes,
+		ttl:           cfg.TTL,
+		janitor:       cfg.JanitorInterval,
+		now:           time.Now,
+		byTrack:       make(map[string]uuid.UUID),
+		byTransaction: make(map[uuid.UUID]uuid.UUID),
+		byCustomer:    make(map[string]map[uuid.UUID]struct{}),
+		fullCustomers: make(map[string]struct{}),
 	}
 }
 
-func (c *OrderCacher) Get(id uuid.UUID) (*models.Order, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if order, ok := c.store[id]; ok {
-		return order, true
-	}
-	return nil, false
+func (c *OrderCacher) Get(id uuid.UUID) (order *models.Order, ok bool) {
+	c.update(func() {
+		order, ok = c.get(id)
+	})
+	return order, ok
 }
 
 func (c *OrderCacher) Set(id uuid.UUID, order *models.Order) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.store[id] = order
+	c.SetWithTTL(id, order, c.ttl)
 }
 
+func (c *OrderCacher) SetWithTTL(id uuid.UUID, order *models.Order, ttl time.Duration) {
+	c.update(func() {
+		c.set(id, order, ttl)
+	})
+}
+
+// SetAll сохраняет заказы по порядку: при нехватке места первыми будут вытеснены первые заказы
 func (c *OrderCacher) SetAll(orders []*models.Order) {
 	for _, order := range orders {
 		c.Set(order.OrderUID, order)
 	}
 }
+
+func (c *OrderCacher) Delete(id uuid.UUID) {
+	c.update(func() {
+		if el, ok := c.store[id]; ok {
+			c.remove(el, ReasonDeleted)
+		}
+	})
+}
+
+func (c *OrderCacher) OnInvalidate(fn InvalidateFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, fn)
+}
+
+func (c *OrderCacher) GetByTrack(track string) (order *models.Order, ok bool) {
+	c.update(func() {
+		id, found := c.byTrack[track]
+		if !found {
+			c.stats.Misses++
+			return
+		}
+		order, ok = c.get(id)
+	})
+	return order, ok
+}
+
+func (c *OrderCacher) GetByTransaction(transaction uuid.UUID) (order *models.Order, ok bool) {
+	c.update(func() {
+		id, found := c.byTransaction[transaction]
+		if !found {
+			c.stats.Misses++
+			return
+		}
+		order, ok = c.get(id)
+	})
+	return order, ok
+}
+
+// GetByCustomer возвращает заказы покупателя, новые первыми, если список покупателя был сохранен через SetCustomerOrders
+// и ни один его заказ с тех пор не был удален из кэша
+func (c *OrderCacher) GetByCustomer(customerID string) (orders []*models.Order, ok bool) {
+	c.update(func() {
+		// истекшие заказы удаляем заранее: удаление делает список неполным
+		now := c.now()
+		for id := range c.byCustomer[customerID] {
+			if el := c.store[id]; el.Value.(*entry).expired(now) {
+				c.remove(el, ReasonExpired)
+			}
+		}
+
+		if _, full := c.fullCustomers[customerID]; !full {
+			c.stats.Misses++
+			return
+		}
+		c.stats.Hits++
+
+		orders = make([]*models.Order, 0, len(c.byCustomer[customerID]))
+		for id := range c.byCustomer[customerID] {
+			el := c.store[id]
+			c.lru.MoveToFront(el)
+			orders = append(orders, el.Value.(*entry).order)
+		}
+		ok = true
+	})
+	if !ok {
+		return nil, false
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].DateCreated.Equal(orders[j].DateCreated) {
+			return orders[i].DateCreated.After(orders[j].DateCreated)
+		}
+		return orders[i].OrderUID.String() > orders[j].OrderUID.String()
+	})
+	return orders, true
+}
+
+func (c *OrderCacher) SetCustomerOrders(customerID string, orders []*models.Order) {
+	c.update(func() {
+		for _, order := range orders {
+			c.set(order.OrderUID, order, c.ttl)
+		}
+
+		// список не поместился в кэш целиком - отдавать его из кэша нельзя
+		for _, order := range orders {
+			if _, ok := c.store[order.OrderUID]; !ok {
+				return
+			}
+		}
+		c.fullCustomers[customerID] = struct{}{}
+	})
+}
+
+func (c *OrderCacher) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// Run удаляет истекшие заказы каждые JanitorInterval, пока не отменен ctx
+func (c *OrderCacher) Run(ctx context.Context) {
+	if c.ttl <= 0 || c.janitor <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.janitor)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
+
+// DeleteExpired удаляет все истекшие заказы
+func (c *OrderCacher) DeleteExpired() {
+	c.update(func() {
+		now := c.now()
+		for el := c.lru.Back(); el != nil; {
+			prev := el.Prev()
+			if el.Value.(*entry).expired(now) {
+				c.remove(el, ReasonExpired)
+			}
+			el = prev
+		}
+	})
+}
+
+// update выполняет fn под c.mu и после снятия блокировки сообщает hooks об удаленных заказах,
+// чтобы обработчики могли обращаться к кэшу
+func (c *OrderCacher) update(fn func()) {
+	c.mu.Lock()
+	fn()
+	removed, hooks := c.removed, c.hooks
+	c.removed = nil
+	c.mu.Unlock()
+
+	for _, r := range removed {
+		for _, hook := range hooks {
+			hook(r.id, r.reason)
+		}
+	}
+}
+
+// get возвращает заказ и отмечает его как недавно использованный; вызывается под c.mu
+func (c *OrderCacher) get(id uuid.UUID) (*models.Order, bool) {
+	el, ok := c.store[id]
+	if ok && el.Value.(*entry).expired(c.now()) {
+		c.remove(el, ReasonExpired)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(el)
+	return el.Value.(*entry).order, true
+}
+
+// set сохраняет заказ, обновляет индексы и вытесняет давно использованные заказы сверх лимитов; вызывается под c.mu
+func (c *OrderCacher) set(id uuid.UUID, order *models.Order, ttl time.Duration) {
+	if el, ok := c.store[id]; ok {
+		// замена версии заказа не удаляет его из кэша
+		c.remove(el, "")
+	}
+
+	e := &entry{id: id, order: order, size: approxOrderSize(order)}
+	if ttl > 0 {
+		e.expiresAt = c.now().Add(ttl)
+	}
+	c.store[id] = c.lru.PushFront(e)
+	c.bytes += e.size
+
+	c.byTrack[order.TrackNumber] = id
+	// транзакция не уникальна: как и БД, индекс отдает самый новый из оплаченных ею заказов
+	if cur, ok := c.store[c.byTransaction[order.Payment.Transaction]]; !ok || !cur.Value.(*entry).order.CreatedAfter(order) {
+		c.byTransaction[order.Payment.Transaction] = id
+	}
+	if c.byCustomer[order.CustomerID] == nil {
+		c.byCustomer[order.CustomerID] = make(map[uuid.UUID]struct{})
+	}
+	c.byCustomer[order.CustomerID][id] = struct{}{}
+
+	for c.overLimit() {
+		c.remove(c.lru.Back(), ReasonEvicted)
+	}
+}
+
+func (c *OrderCacher) overLimit() bool {
+	if c.lru.Len() == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// remove удаляет запись из кэша и ее значения из индексов.
+// Пустой reason - запись заменяется новой версией заказа, hooks не вызываются
+func (c *OrderCacher) remove(el *list.Element, reason string) {
+	e := el.Value.(*entry)
+	c.lru.Remove(el)
+	delete(c.store, e.id)
+	c.bytes -= e.size
+
+	old := e.order
+	if c.byTrack[old.TrackNumber] == e.id {
+		delete(c.byTrack, old.TrackNumber)
+	}
+	if c.byTransaction[old.Payment.Transaction] == e.id {
+		delete(c.byTransaction, old.Payment.Transaction)
+	}
+	delete(c.byCustomer[old.CustomerID], e.id)
+	if len(c.byCustomer[old.CustomerID]) == 0 {
+		delete(c.byCustomer, old.CustomerID)
+	}
+
+	if reason == "" {
+		return
+	}
+	// без удаленного заказа список покупателя неполный
+	delete(c.fullCustomers, old.CustomerID)
+
+	switch reason {
+	case ReasonEvicted:
+		c.stats.Evictions++
+	case ReasonExpired:
+		c.stats.Expirations++
+	}
+	c.removed = append(c.removed, removal{id: e.id, reason: reason})
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOrder(track, customer string, created time.Time) *models.Order {
+	return &models.Order{
+		OrderUID:    uuid.Must(uuid.NewV4()),
+		TrackNumber: track,
+		CustomerID:  customer,
+		DateCreated: created,
+		Payment:     models.Payment{Transaction: uuid.Must(uuid.NewV4())},
+	}
+}
+
+func TestOrderCacher_SecondaryIndexes(t *testing.T) {
+	c := NewOrderCacher(&CacheConfig{})
+	order := newTestOrder("WBILMTESTTRACK", "test", time.Now())
+	c.Set(order.OrderUID, order)
+
+	got, ok := c.GetByTrack("WBILMTESTTRACK")
+	assert.True(t, ok)
+	assert.Equal(t, order, got)
+
+	got, ok = c.GetByTransaction(order.Payment.Transaction)
+	assert.True(t, ok)
+	assert.Equal(t, order, got)
+
+	// новая версия заказа заменяет значения в индексах
+	updated := *order
+	updated.TrackNumber = "WBILMNEWTRACK"
+	updated.Payment.Transaction = uuid.Must(uuid.NewV4())
+	c.Set(updated.OrderUID, &updated)
+
+	_, ok = c.GetByTrack("WBILMTESTTRACK")
+	assert.False(t, ok)
+	_, ok = c.GetByTransaction(order.Payment.Transaction)
+	assert.False(t, ok)
+
+	got, ok = c.GetByTrack("WBILMNEWTRACK")
+	assert.True(t, ok)
+	assert.Equal(t, &updated, got)
+}
+
+func TestOrderCacher_GetByTransaction_Newest(t *testing.T) {
+	c := NewOrderCacher(&CacheConfig{})
+	now := time.Now()
+	older := newTestOrder("TRACK1", "test", now.Add(-time.Hour))
+	newer := newTestOrder("TRACK2", "test", now)
+	older.Payment.Transaction = newer.Payment.Transaction
+
+	// порядок записи в кэш не важен: по транзакции отдается самый новый заказ
+	c.Set(newer.OrderUID, newer)
+	c.Set(older.OrderUID, older)
+
+	got, ok := c.GetByTransaction(newer.Payment.Transaction)
+	assert.True(t, ok)
+	assert.Equal(t, newer, got)
+}
+
+func TestOrderCacher_GetByCustomer(t *testing.T) {
+	c := NewOrderCacher(&CacheConfig{})
+	now := time.Now()
+	older := newTestOrder("TRACK1", "test", now.Add(-time.Hour))
+	newer := newTestOrder("TRACK2", "test", now)
+
+	// отдельные заказы не делают список покупателя полным
+	c.Set(older.OrderUID, older)
+	_, ok := c.GetByCustomer("test")
+	assert.False(t, ok)
+
+	c.SetCustomerOrders("test", []*models.Order{older})
+	c.Set(newer.OrderUID, newer)
+
+	orders, ok := c.GetByCustomer("test")
+	assert.True(t, ok)
+	assert.Equal(t, []*models.Order{newer, older}, orders)
+
+	// заказ, переданный другому покупателю, пропадает из его списка
+	moved := *newer
+	moved.CustomerID = "other"
+	c.Set(moved.OrderUID, &moved)
+
+	orders, ok = c.GetByCustomer("test")
+	assert.True(t, ok)
+	assert.Equal(t, []*models.Order{older}, orders)
+}
+
+func TestOrderCacher_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewOrderCacher(&CacheConfig{MaxEntries: 2})
+	now := time.Now()
+	first := newTestOrder("TRACK1", "test", now)
+	second := newTestOrder("TRACK2", "test", now)
+	third := newTestOrder("TRACK3", "other", now)
+
+	c.SetCustomerOrders("test", []*models.Order{first, second})
+
+	// first использован недавно, поэтому вытесняется second
+	_, ok := c.Get(first.OrderUID)
+	assert.True(t, ok)
+	c.Set(third.OrderUID, third)
+
+	_, ok = c.Get(second.OrderUID)
+	assert.False(t, ok)
+	_, ok = c.GetByTrack("TRACK2")
+	assert.False(t, ok)
+
+	// список покупателя после вытеснения неполный
+	_, ok = c.GetByCustomer("test")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(3), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, 2, stats.Entries)
+}
+
+func TestOrderCacher_MaxBytes(t *testing.T) {
+	order := newTestOrder("TRACK1", "test", time.Now())
+	size := approxOrderSize(order)
+	c := NewOrderCacher(&CacheConfig{MaxBytes: 2*size + size/2})
+
+	for i := 0; i < 5; i++ {
+		o := newTestOrder("TRACK1", "test", time.Now())
+		c.Set(o.OrderUID, o)
+	}
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, 2*size, stats.Bytes)
+	assert.Equal(t, uint64(3), stats.Evictions)
+}
+
+func TestOrderCacher_TTL(t *testing.T) {
+	now := time.Now()
+	c := NewOrderCacher(&CacheConfig{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+
+	var removed []string
+	c.OnInvalidate(func(id uuid.UUID, reason string) {
+		removed = append(removed, reason)
+	})
+
+	short := newTestOrder("TRACK1", "test", now)
+	long := newTestOrder("TRACK2", "test", now)
+	forever := newTestOrder("TRACK3", "test", now)
+	c.Set(short.OrderUID, short)
+	c.SetWithTTL(long.OrderUID, long, time.Hour)
+	c.SetWithTTL(forever.OrderUID, forever, 0)
+
+	now = now.Add(2 * time.Minute)
+
+	_, ok := c.Get(short.OrderUID)
+	assert.False(t, ok)
+	_, ok = c.GetByTrack("TRACK2")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Hour)
+	c.DeleteExpired()
+
+	_, ok = c.Get(long.OrderUID)
+	assert.False(t, ok)
+	_, ok = c.Get(forever.OrderUID)
+	assert.True(t, ok)
+
+	assert.Equal(t, []string{ReasonExpired, ReasonExpired}, removed)
+	assert.Equal(t, uint64(2), c.Stats().Expirations)
+}
+
+func TestOrderCacher_Delete(t *testing.T) {
+	c := NewOrderCacher(&CacheConfig{})
+	order := newTestOrder("TRACK1", "test", time.Now())
+	c.SetCustomerOrders("test", []*models.Order{order})
+
+	var removed []uuid.UUID
+	c.OnInvalidate(func(id uuid.UUID, reason string) {
+		assert.Equal(t, ReasonDeleted, reason)
+		// обработчик вызывается без блокировки и может обращаться к кэшу
+		_, ok := c.Get(id)
+		assert.False(t, ok)
+		removed = append(removed, id)
+	})
+
+	c.Delete(order.OrderUID)
+	assert.Equal(t, []uuid.UUID{order.OrderUID}, removed)
+
+	_, ok := c.GetByTrack("TRACK1")
+	assert.False(t, ok)
+	_, ok = c.GetByCustomer("test")
+	assert.False(t, ok)
+}
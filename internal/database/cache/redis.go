@@ -0,0 +1,457 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// версия формата заказа в Redis: при несовпадении запись считается промахом
+const redisFormatVersion = 1
+
+// redisEntry запись заказа в Redis
+type redisEntry struct {
+	Version int           `json:"v"`
+	Order   *models.Order `json:"order"`
+}
+
+// RedisCache кэш заказов в Redis, общий для всех реплик сервиса.
+// Ошибки Redis не прерывают обработку запросов: они логируются, а обращение считается промахом
+type RedisCache struct {
+	client  *redis.Client
+	prefix  string
+	timeout time.Duration
+	ttl     time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+
+	mu    sync.Mutex
+	hooks []InvalidateFunc
+}
+
+func NewRedisCache(cfg *CacheConfig) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	rc := &RedisCache{
+		client:  client,
+		prefix:  cfg.Redis.Prefix,
+		timeout: cfg.Redis.Timeout,
+		ttl:     cfg.TTL,
+	}
+
+	ctx, cancel := rc.opContext()
+	defer cancel()
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("[NewRedisCache|ping] , %w", err)
+	}
+	return rc, nil
+}
+
+func (rc *RedisCache) Get(id uuid.UUID) (*models.Order, bool) {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	order, err := rc.load(ctx, id)
+	if err != nil {
+		rc.logError("get order", err)
+	}
+	if order == nil {
+		rc.misses.Add(1)
+		return nil, false
+	}
+	rc.hits.Add(1)
+	return order, true
+}
+
+func (rc *RedisCache) Set(id uuid.UUID, order *models.Order) {
+	rc.SetWithTTL(id, order, rc.ttl)
+}
+
+func (rc *RedisCache) SetWithTTL(id uuid.UUID, order *models.Order, ttl time.Duration) {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	err := rc.store(ctx, id, order, ttl)
+	if err != nil {
+		rc.logError("set order", err)
+	}
+}
+
+func (rc *RedisCache) SetAll(orders []*models.Order) {
+	for _, order := range orders {
+		rc.Set(order.OrderUID, order)
+	}
+}
+
+// Delete удаляет заказ и сообщает о нем остальным репликам через канал инвалидаций
+func (rc *RedisCache) Delete(id uuid.UUID) {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	err := rc.remove(ctx, id)
+	if err != nil {
+		rc.logError("delete order", err)
+	}
+
+	err = rc.client.Publish(ctx, rc.invalidateChannel(), id.String()).Err()
+	if err != nil {
+		rc.logError("publish invalidation", err)
+	}
+
+	rc.mu.Lock()
+	hooks := rc.hooks
+	rc.mu.Unlock()
+	for _, hook := range hooks {
+		hook(id, ReasonDeleted)
+	}
+}
+
+// OnInvalidate регистрирует обработчик удалений через Delete этой реплики.
+// Об истечении TTL и вытеснении Redis не сообщает
+func (rc *RedisCache) OnInvalidate(fn InvalidateFunc) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.hooks = append(rc.hooks, fn)
+}
+
+func (rc *RedisCache) GetByTrack(track string) (*models.Order, bool) {
+	return rc.getByIndex(rc.key("track", track))
+}
+
+func (rc *RedisCache) GetByTransaction(transaction uuid.UUID) (*models.Order, bool) {
+	return rc.getByIndex(rc.key("transaction", transaction.String()))
+}
+
+// GetByCustomer возвращает заказы покупателя, новые первыми, если список покупателя был сохранен через SetCustomerOrders
+// и ни один его заказ с тех пор не пропал из кэша
+func (rc *RedisCache) GetByCustomer(customerID string) ([]*models.Order, bool) {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	orders, err := rc.loadCustomer(ctx, customerID)
+	if err != nil {
+		rc.logError("get customer orders", err)
+	}
+	if orders == nil {
+		rc.misses.Add(1)
+		return nil, false
+	}
+	rc.hits.Add(1)
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].DateCreated.Equal(orders[j].DateCreated) {
+			return orders[i].DateCreated.After(orders[j].DateCreated)
+		}
+		return orders[i].OrderUID.String() > orders[j].OrderUID.String()
+	})
+	return orders, true
+}
+
+func (rc *RedisCache) SetCustomerOrders(customerID string, orders []*models.Order) {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	for _, order := range orders {
+		err := rc.store(ctx, order.OrderUID, order, rc.ttl)
+		if err != nil {
+			rc.logError("set customer orders", err)
+			return
+		}
+	}
+
+	// отметка полного списка истекает вместе с множеством заказов покупателя, а не позже него
+	_, err := rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, rc.key("customer_full", customerID), 1, rc.ttl)
+		rc.expire(ctx, pipe, rc.key("customer", customerID), rc.ttl)
+		return nil
+	})
+	if err != nil {
+		rc.logError("set customer orders", err)
+	}
+}
+
+// Stats возвращает попадания и промахи этой реплики; размер кэша отслеживает сам Redis
+func (rc *RedisCache) Stats() Stats {
+	return Stats{
+		Hits:   rc.hits.Load(),
+		Misses: rc.misses.Load(),
+	}
+}
+
+// Subscribe вызывает fn для каждого заказа, удаленного из кэша любой репликой, пока не отменен ctx
+func (rc *RedisCache) Subscribe(ctx context.Context, fn func(id uuid.UUID)) {
+	sub := rc.client.Subscribe(ctx, rc.invalidateChannel())
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			id, err := uuid.FromString(msg.Payload)
+			if err != nil {
+				rc.logError("parse invalidation", err)
+				continue
+			}
+			fn(id)
+		}
+	}
+}
+
+// tryLockWarmup разрешает прогрев общего кэша только одной реплике на время TTL кэша
+func (rc *RedisCache) tryLockWarmup() bool {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	ttl := rc.ttl
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	locked, err := rc.client.SetNX(ctx, rc.prefix+":warmup", 1, ttl).Result()
+	if err != nil {
+		rc.logError("lock warmup", err)
+		return true
+	}
+	return locked
+}
+
+func (rc *RedisCache) Close() error {
+	return rc.client.Close()
+}
+
+func (rc *RedisCache) getByIndex(indexKey string) (*models.Order, bool) {
+	ctx, cancel := rc.opContext()
+	defer cancel()
+
+	order, err := rc.loadByIndex(ctx, indexKey)
+	if err != nil {
+		rc.logError("get order by index", err)
+	}
+	if order == nil {
+		rc.misses.Add(1)
+		return nil, false
+	}
+	rc.hits.Add(1)
+	return order, true
+}
+
+func (rc *RedisCache) loadByIndex(ctx context.Context, indexKey string) (*models.Order, error) {
+	raw, err := rc.client.Get(ctx, indexKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.FromString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := rc.load(ctx, id)
+	if err != nil || order == nil {
+		return nil, err
+	}
+	// индекс мог остаться от предыдущей версии заказа
+	if indexKey != rc.key("track", order.TrackNumber) && indexKey != rc.key("transaction", order.Payment.Transaction.String()) {
+		return nil, nil
+	}
+	return order, nil
+}
+
+// load читает заказ; отсутствующий заказ и запись другого формата возвращаются как nil без ошибки
+func (rc *RedisCache) load(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	data, err := rc.client.Get(ctx, rc.orderKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeRedisEntry(data)
+}
+
+func (rc *RedisCache) loadCustomer(ctx context.Context, customerID string) ([]*models.Order, error) {
+	fullKey := rc.key("customer_full", customerID)
+
+	n, err := rc.client.Exists(ctx, fullKey).Result()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+
+	ids, err := rc.client.SMembers(ctx, rc.key("customer", customerID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*models.Order, 0, len(ids))
+	if len(ids) == 0 {
+		return orders, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = rc.key("order", id)
+	}
+	values, err := rc.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []any
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			// заказ истек или вытеснен
+			stale = append(stale, ids[i])
+			continue
+		}
+		order, err := decodeRedisEntry([]byte(s))
+		if err != nil || order == nil || order.CustomerID != customerID {
+			stale = append(stale, ids[i])
+			continue
+		}
+		orders = append(orders, order)
+	}
+	if len(stale) == 0 {
+		return orders, nil
+	}
+
+	// пропавшие заказы убираем из множества, а список без них неполный
+	_, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SRem(ctx, rc.key("customer", customerID), stale...)
+		pipe.Del(ctx, fullKey)
+		return nil
+	})
+	return nil, err
+}
+
+func (rc *RedisCache) store(ctx context.Context, id uuid.UUID, order *models.Order, ttl time.Duration) error {
+	data, err := json.Marshal(redisEntry{Version: redisFormatVersion, Order: order})
+	if err != nil {
+		return err
+	}
+
+	old, err := rc.load(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// транзакция не уникальна: как и БД, индекс отдает самый новый из оплаченных ею заказов
+	transactionKey := rc.key("transaction", order.Payment.Transaction.String())
+	indexed, err := rc.loadByIndex(ctx, transactionKey)
+	if err != nil {
+		return err
+	}
+	newest := indexed == nil || indexed.OrderUID == id || !indexed.CreatedAfter(order)
+
+	_, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// уберем индексы предыдущей версии заказа
+		if old != nil {
+			if old.TrackNumber != order.TrackNumber {
+				pipe.Del(ctx, rc.key("track", old.TrackNumber))
+			}
+			if old.Payment.Transaction != order.Payment.Transaction {
+				pipe.Del(ctx, rc.key("transaction", old.Payment.Transaction.String()))
+			}
+			if old.CustomerID != order.CustomerID {
+				pipe.SRem(ctx, rc.key("customer", old.CustomerID), id.String())
+				pipe.Del(ctx, rc.key("customer_full", old.CustomerID))
+			}
+		}
+
+		pipe.Set(ctx, rc.orderKey(id), data, ttl)
+		pipe.Set(ctx, rc.key("track", order.TrackNumber), id.String(), ttl)
+		if newest {
+			pipe.Set(ctx, transactionKey, id.String(), ttl)
+		}
+		pipe.SAdd(ctx, rc.key("customer", order.CustomerID), id.String())
+		// заказы хранятся с одним TTL: множество покупателя живет столько же, сколько его последний сохраненный заказ
+		rc.expire(ctx, pipe, rc.key("customer", order.CustomerID), ttl)
+		return nil
+	})
+	return err
+}
+
+// expire задает срок хранения ключа, 0 - без срока
+func (rc *RedisCache) expire(ctx context.Context, pipe redis.Pipeliner, key string, ttl time.Duration) {
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+		return
+	}
+	pipe.Persist(ctx, key)
+}
+
+func (rc *RedisCache) remove(ctx context.Context, id uuid.UUID) error {
+	old, err := rc.load(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, rc.orderKey(id))
+		if old != nil {
+			pipe.Del(ctx, rc.key("track", old.TrackNumber))
+			pipe.Del(ctx, rc.key("transaction", old.Payment.Transaction.String()))
+			pipe.SRem(ctx, rc.key("customer", old.CustomerID), id.String())
+			// без удаленного заказа список покупателя неполный
+			pipe.Del(ctx, rc.key("customer_full", old.CustomerID))
+		}
+		return nil
+	})
+	return err
+}
+
+func decodeRedisEntry(data []byte) (*models.Order, error) {
+	var e redisEntry
+	err := json.Unmarshal(data, &e)
+	if err != nil {
+		return nil, err
+	}
+	if e.Version != redisFormatVersion {
+		return nil, nil
+	}
+	return e.Order, nil
+}
+
+func (rc *RedisCache) key(kind, value string) string {
+	return rc.prefix + ":" + kind + ":" + value
+}
+
+func (rc *RedisCache) orderKey(id uuid.UUID) string {
+	return rc.key("order", id.String())
+}
+
+func (rc *RedisCache) invalidateChannel() string {
+	return rc.prefix + ":invalidate"
+}
+
+func (rc *RedisCache) opContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), rc.timeout)
+}
+
+func (rc *RedisCache) logError(op string, err error) {
+	slog.Warn("Redis cache operation failed", "operation", op, "error", err)
+}
@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisConfig(t *testing.T) (*CacheConfig, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return &CacheConfig{
+		TTL:      time.Minute,
+		LocalTTL: time.Minute,
+		Redis: RedisConfig{
+			Addr:    mr.Addr(),
+			Prefix:  "test",
+			Timeout: time.Second,
+		},
+	}, mr
+}
+
+func TestRedisCache_SetGet(t *testing.T) {
+	cfg, mr := newTestRedisConfig(t)
+	rc, err := NewRedisCache(cfg)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	order := newTestOrder("WBILMTESTTRACK", "test", time.Now().UTC().Truncate(time.Second))
+	rc.Set(order.OrderUID, order)
+
+	// ключи сервиса в своем пространстве имен
+	assert.True(t, mr.Exists("test:order:"+order.OrderUID.String()))
+	assert.Equal(t, time.Minute, mr.TTL("test:order:"+order.OrderUID.String()))
+
+	got, ok := rc.Get(order.OrderUID)
+	assert.True(t, ok)
+	assert.Equal(t, order, got)
+
+	got, ok = rc.GetByTrack("WBILMTESTTRACK")
+	assert.True(t, ok)
+	assert.Equal(t, order.OrderUID, got.OrderUID)
+
+	got, ok = rc.GetByTransaction(order.Payment.Transaction)
+	assert.True(t, ok)
+	assert.Equal(t, order.OrderUID, got.OrderUID)
+
+	// после истечения TTL заказ пропадает
+	mr.FastForward(2 * time.Minute)
+	_, ok = rc.Get(order.OrderUID)
+	assert.False(t, ok)
+
+	stats := rc.Stats()
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestRedisCache_ReplaceAndDelete(t *testing.T) {
+	cfg, _ := newTestRedisConfig(t)
+	rc, err := NewRedisCache(cfg)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	order := newTestOrder("TRACK1", "test", time.Now().UTC())
+	rc.SetCustomerOrders("test", []*models.Order{order})
+
+	orders, ok := rc.GetByCustomer("test")
+	assert.True(t, ok)
+	assert.Len(t, orders, 1)
+
+	updated := *order
+	updated.TrackNumber = "TRACK2"
+	rc.Set(updated.OrderUID, &updated)
+
+	_, ok = rc.GetByTrack("TRACK1")
+	assert.False(t, ok)
+	_, ok = rc.GetByTrack("TRACK2")
+	assert.True(t, ok)
+
+	var removed []uuid.UUID
+	rc.OnInvalidate(func(id uuid.UUID, reason string) {
+		assert.Equal(t, ReasonDeleted, reason)
+		removed = append(removed, id)
+	})
+	rc.Delete(order.OrderUID)
+
+	assert.Equal(t, []uuid.UUID{order.OrderUID}, removed)
+	_, ok = rc.Get(order.OrderUID)
+	assert.False(t, ok)
+	_, ok = rc.GetByCustomer("test")
+	assert.False(t, ok)
+}
+
+func TestRedisCache_GetByTransaction_Newest(t *testing.T) {
+	cfg, _ := newTestRedisConfig(t)
+	rc, err := NewRedisCache(cfg)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	now := time.Now().UTC()
+	older := newTestOrder("TRACK1", "test", now.Add(-time.Hour))
+	newer := newTestOrder("TRACK2", "test", now)
+	older.Payment.Transaction = newer.Payment.Transaction
+
+	rc.Set(newer.OrderUID, newer)
+	rc.Set(older.OrderUID, older)
+
+	got, ok := rc.GetByTransaction(newer.Payment.Transaction)
+	assert.True(t, ok)
+	assert.Equal(t, newer.OrderUID, got.OrderUID)
+}
+
+func TestRedisCache_CustomerSet(t *testing.T) {
+	cfg, mr := newTestRedisConfig(t)
+	rc, err := NewRedisCache(cfg)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	first := newTestOrder("TRACK1", "test", time.Now().UTC())
+	second := newTestOrder("TRACK2", "test", time.Now().UTC())
+	rc.SetCustomerOrders("test", []*models.Order{first, second})
+	assert.Equal(t, time.Minute, mr.TTL("test:customer:test"))
+	assert.Equal(t, time.Minute, mr.TTL("test:customer_full:test"))
+
+	// вытесненный заказ убирается из множества при чтении, список покупателя - промах
+	mr.Del("test:order:" + first.OrderUID.String())
+	_, ok := rc.GetByCustomer("test")
+	assert.False(t, ok)
+	members, err := mr.Members("test:customer:test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.OrderUID.String()}, members)
+
+	// новый заказ продлевает множество, после истечения всех заказов оно пропадает
+	mr.FastForward(30 * time.Second)
+	third := newTestOrder("TRACK3", "test", time.Now().UTC())
+	rc.Set(third.OrderUID, third)
+	assert.Equal(t, time.Minute, mr.TTL("test:customer:test"))
+
+	mr.FastForward(2 * time.Minute)
+	assert.False(t, mr.Exists("test:customer:test"))
+}
+
+func TestRedisCache_WarmupLock(t *testing.T) {
+	cfg, _ := newTestRedisConfig(t)
+	first, err := NewRedisCache(cfg)
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewRedisCache(cfg)
+	require.NoError(t, err)
+	defer second.Close()
+
+	assert.True(t, NeedsWarmup(first))
+	assert.False(t, NeedsWarmup(second))
+}
+
+func TestTieredCache_InvalidatesOtherReplicas(t *testing.T) {
+	cfg, _ := newTestRedisConfig(t)
+	first, err := NewTieredCache(cfg)
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewTieredCache(cfg)
+	require.NoError(t, err)
+	defer second.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go second.Run(ctx)
+
+	order := newTestOrder("TRACK1", "test", time.Now().UTC())
+	first.Set(order.OrderUID, order)
+
+	// вторая реплика берет заказ из Redis и кладет в локальный уровень
+	_, ok := second.Get(order.OrderUID)
+	assert.True(t, ok)
+	_, ok = second.Local.Get(order.OrderUID)
+	assert.True(t, ok)
+
+	// подписка могла еще не установиться - удаляем, пока вторая реплика не получит рассылку
+	assert.Eventually(t, func() bool {
+		first.Delete(order.OrderUID)
+		_, ok := second.Local.Get(order.OrderUID)
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	_, ok = second.Get(order.OrderUID)
+	assert.False(t, ok)
+	assert.NotNil(t, second.Stats().Remote)
+}
@@ -0,0 +1,28 @@
+package cache
+
+import "github.com/orders_api/internal/models"
+
+// примерные накладные расходы на структуры без учета строк
+const (
+	orderOverhead = 512
+	itemOverhead  = 128
+)
+
+// approxOrderSize оценивает, сколько памяти занимает заказ в кэше
+func approxOrderSize(o *models.Order) int64 {
+	size := orderOverhead +
+		len(o.TrackNumber) + len(o.Entry) + len(o.Locale) + len(o.InternalSignature) + len(o.CustomerID) +
+		len(o.DeliveryService) + len(o.Shardkey) + len(o.OofShard) + len(o.Status)
+
+	d := &o.Delivery
+	size += len(d.Name) + len(d.Phone) + len(d.Zip) + len(d.City) + len(d.Address) + len(d.Region) + len(d.Email)
+
+	p := &o.Payment
+	size += len(p.RequestID) + len(p.Currency) + len(p.Provider) + len(p.Bank)
+
+	for i := range o.Items {
+		it := &o.Items[i]
+		size += itemOverhead + len(it.TrackNumber) + len(it.Rid) + len(it.Name) + len(it.Size) + len(it.Brand)
+	}
+	return int64(size)
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+)
+
+// Формат снапшота: magic, версия формата (uint16), CRC32 сжатого тела (uint32), тело - gzip(JSON snapshotBody)
+const (
+	snapshotMagic   = "OCSN"
+	snapshotVersion = 1
+	snapshotHeader  = len(snapshotMagic) + 2 + 4
+)
+
+var (
+	ErrSnapshotFormat   = errors.New("unknown cache snapshot format")
+	ErrSnapshotChecksum = errors.New("cache snapshot checksum mismatch")
+)
+
+// SnapshotInfo сведения о загруженном снапшоте
+type SnapshotInfo struct {
+	TakenAt   time.Time   // когда снапшот был сохранен
+	Watermark time.Time   // самый поздний date_created среди заказов снапшота
+	Orders    []uuid.UUID // загруженные заказы
+}
+
+// Snapshotter кэш, который умеет сохранять заказы на диск и загружать их при старте
+type Snapshotter interface {
+	SaveSnapshot(path string) error
+	LoadSnapshot(path string) (*SnapshotInfo, error)
+}
+
+type snapshotBody struct {
+	TakenAt   time.Time       `json:"taken_at"`
+	Watermark time.Time       `json:"watermark"`
+	Entries   []snapshotEntry `json:"entries"`
+}
+
+type snapshotEntry struct {
+	Order     *models.Order `json:"order"`
+	ExpiresAt time.Time     `json:"expires_at"` // нулевое значение - без срока хранения
+}
+
+// SaveSnapshot сохраняет заказы кэша в файл path. Файл заменяется атомарно,
+// поэтому при сбое во время записи остается предыдущий снапшот
+func (c *OrderCacher) SaveSnapshot(path string) error {
+	body := c.snapshot()
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	err := json.NewEncoder(zw).Encode(body)
+	if err != nil {
+		return fmt.Errorf("[SaveSnapshot|encode]: %w", err)
+	}
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("[SaveSnapshot|compress]: %w", err)
+	}
+
+	header := make([]byte, snapshotHeader)
+	copy(header, snapshotMagic)
+	binary.BigEndian.PutUint16(header[4:], snapshotVersion)
+	binary.BigEndian.PutUint32(header[6:], crc32.ChecksumIEEE(compressed.Bytes()))
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("[SaveSnapshot|create dir]: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("[SaveSnapshot|create temp file]: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(header)
+	if err == nil {
+		_, err = tmp.Write(compressed.Bytes())
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("[SaveSnapshot|write]: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("[SaveSnapshot|rename]: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot загружает в кэш заказы из снапшота path. Истекшие заказы пропускаются,
+// остальные сохраняют исходный срок хранения и порядок вытеснения
+func (c *OrderCacher) LoadSnapshot(path string) (*SnapshotInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[LoadSnapshot|read]: %w", err)
+	}
+
+	if len(data) < snapshotHeader || string(data[:4]) != snapshotMagic {
+		return nil, fmt.Errorf("[LoadSnapshot|header]: %w", ErrSnapshotFormat)
+	}
+	if version := binary.BigEndian.Uint16(data[4:]); version != snapshotVersion {
+		return nil, fmt.Errorf("[LoadSnapshot|version %d]: %w", version, ErrSnapshotFormat)
+	}
+	compressed := data[snapshotHeader:]
+	if binary.BigEndian.Uint32(data[6:]) != crc32.ChecksumIEEE(compressed) {
+		return nil, fmt.Errorf("[LoadSnapshot|checksum]: %w", ErrSnapshotChecksum)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("[LoadSnapshot|decompress]: %w", err)
+	}
+	var body snapshotBody
+	err = json.NewDecoder(zr).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("[LoadSnapshot|decode]: %w", err)
+	}
+
+	info := &SnapshotInfo{TakenAt: body.TakenAt, Watermark: body.Watermark}
+	c.update(func() {
+		now := c.now()
+		for _, e := range body.Entries {
+			var ttl time.Duration
+			if !e.ExpiresAt.IsZero() {
+				ttl = e.ExpiresAt.Sub(now)
+				if ttl <= 0 {
+					continue
+				}
+			}
+			c.set(e.Order.OrderUID, e.Order, ttl)
+			info.Orders = append(info.Orders, e.Order.OrderUID)
+		}
+	})
+	return info, nil
+}
+
+// snapshot копирует заказы от давно использованных к недавно использованным, чтобы при загрузке сохранился порядок вытеснения
+func (c *OrderCacher) snapshot() *snapshotBody {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body := &snapshotBody{
+		TakenAt: c.now(),
+		Entries: make([]snapshotEntry, 0, c.lru.Len()),
+	}
+	for el := c.lru.Back(); el != nil; el = el.Prev() {
+		e := el.Value.(*entry)
+		body.Entries = append(body.Entries, snapshotEntry{Order: e.order, ExpiresAt: e.expiresAt})
+		if e.order.DateCreated.After(body.Watermark) {
+			body.Watermark = e.order.DateCreated
+		}
+	}
+	return body
+}
+
+func (t *TieredCache) SaveSnapshot(path string) error {
+	return t.Local.SaveSnapshot(path)
+}
+
+func (t *TieredCache) LoadSnapshot(path string) (*SnapshotInfo, error) {
+	return t.Local.LoadSnapshot(path)
+}
+
+// RunSnapshots сохраняет снапшот кэша каждые interval, пока не отменен ctx
+func RunSnapshots(ctx context.Context, s Snapshotter, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.SaveSnapshot(path)
+			if err != nil {
+				slog.Error("Failed to save cache snapshot", "path", path, "error", err)
+				continue
+			}
+			slog.Debug("Saved cache snapshot", "path", path)
+		}
+	}
+}
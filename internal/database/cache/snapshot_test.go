@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderCacher_SnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.snap")
+	now := time.Now().Truncate(time.Second)
+
+	src := NewOrderCacher(&CacheConfig{MaxEntries: 2, TTL: time.Hour})
+	first := newTestOrder("TRACK1", "test", now.Add(-time.Hour))
+	second := newTestOrder("TRACK2", "test", now)
+	src.Set(first.OrderUID, first)
+	src.Set(second.OrderUID, second)
+	// first становится недавно использованным
+	src.Get(first.OrderUID)
+	require.NoError(t, src.SaveSnapshot(path))
+
+	dst := NewOrderCacher(&CacheConfig{MaxEntries: 2, TTL: time.Hour})
+	info, err := dst.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{second.OrderUID, first.OrderUID}, info.Orders)
+	assert.True(t, info.Watermark.Equal(now))
+
+	// порядок вытеснения сохранился: первым вытесняется давно использованный second
+	third := newTestOrder("TRACK3", "test", now)
+	dst.Set(third.OrderUID, third)
+	_, ok := dst.GetByTrack("TRACK2")
+	assert.False(t, ok)
+
+	got, ok := dst.GetByTrack("TRACK1")
+	require.True(t, ok)
+	assert.Equal(t, first.OrderUID, got.OrderUID)
+	assert.True(t, got.DateCreated.Equal(first.DateCreated))
+}
+
+func TestOrderCacher_SnapshotSkipsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.snap")
+	now := time.Now()
+
+	src := NewOrderCacher(&CacheConfig{TTL: time.Minute})
+	order := newTestOrder("TRACK1", "test", now)
+	src.Set(order.OrderUID, order)
+	require.NoError(t, src.SaveSnapshot(path))
+
+	dst := NewOrderCacher(&CacheConfig{TTL: time.Minute})
+	dst.now = func() time.Time { return now.Add(2 * time.Minute) }
+	info, err := dst.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Empty(t, info.Orders)
+	assert.Equal(t, 0, dst.Stats().Entries)
+}
+
+func TestOrderCacher_SnapshotCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.snap")
+
+	src := NewOrderCacher(&CacheConfig{})
+	order := newTestOrder("TRACK1", "test", time.Now())
+	src.Set(order.OrderUID, order)
+	require.NoError(t, src.SaveSnapshot(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// испорченное тело
+	broken := append([]byte{}, data...)
+	broken[len(broken)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, broken, 0o644))
+	_, err = NewOrderCacher(&CacheConfig{}).LoadSnapshot(path)
+	assert.ErrorIs(t, err, ErrSnapshotChecksum)
+
+	// другая версия формата
+	broken = append([]byte{}, data...)
+	broken[5] = snapshotVersion + 1
+	require.NoError(t, os.WriteFile(path, broken, 0o644))
+	_, err = NewOrderCacher(&CacheConfig{}).LoadSnapshot(path)
+	assert.ErrorIs(t, err, ErrSnapshotFormat)
+}
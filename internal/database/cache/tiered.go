@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+)
+
+// TieredCache локальный LRU перед общим кэшем в Redis.
+// Удаления рассылаются репликам через Redis, а короткий LocalTTL ограничивает устаревание, если рассылка потерялась
+type TieredCache struct {
+	Local  *OrderCacher
+	Remote *RedisCache
+}
+
+func NewTieredCache(cfg *CacheConfig) (*TieredCache, error) {
+	remote, err := NewRedisCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	localCfg := *cfg
+	localCfg.TTL = cfg.LocalTTL
+	return &TieredCache{
+		Local:  NewOrderCacher(&localCfg),
+		Remote: remote,
+	}, nil
+}
+
+func (t *TieredCache) Get(id uuid.UUID) (*models.Order, bool) {
+	if order, ok := t.Local.Get(id); ok {
+		return order, true
+	}
+	order, ok := t.Remote.Get(id)
+	if ok {
+		t.Local.Set(id, order)
+	}
+	return order, ok
+}
+
+func (t *TieredCache) Set(id uuid.UUID, order *models.Order) {
+	t.Remote.Set(id, order)
+	t.Local.Set(id, order)
+}
+
+func (t *TieredCache) SetWithTTL(id uuid.UUID, order *models.Order, ttl time.Duration) {
+	t.Remote.SetWithTTL(id, order, ttl)
+
+	localTTL := t.Local.ttl
+	if ttl > 0 && (localTTL == 0 || ttl < localTTL) {
+		localTTL = ttl
+	}
+	t.Local.SetWithTTL(id, order, localTTL)
+}
+
+func (t *TieredCache) SetAll(orders []*models.Order) {
+	t.Remote.SetAll(orders)
+	t.Local.SetAll(orders)
+}
+
+func (t *TieredCache) Delete(id uuid.UUID) {
+	t.Local.Delete(id)
+	t.Remote.Delete(id)
+}
+
+func (t *TieredCache) OnInvalidate(fn InvalidateFunc) {
+	t.Local.OnInvalidate(fn)
+	t.Remote.OnInvalidate(fn)
+}
+
+func (t *TieredCache) GetByTrack(track string) (*models.Order, bool) {
+	if order, ok := t.Local.GetByTrack(track); ok {
+		return order, true
+	}
+	order, ok := t.Remote.GetByTrack(track)
+	if ok {
+		t.Local.Set(order.OrderUID, order)
+	}
+	return order, ok
+}
+
+func (t *TieredCache) GetByTransaction(transaction uuid.UUID) (*models.Order, bool) {
+	if order, ok := t.Local.GetByTransaction(transaction); ok {
+		return order, true
+	}
+	order, ok := t.Remote.GetByTransaction(transaction)
+	if ok {
+		t.Local.Set(order.OrderUID, order)
+	}
+	return order, ok
+}
+
+func (t *TieredCache) GetByCustomer(customerID string) ([]*models.Order, bool) {
+	if orders, ok := t.Local.GetByCustomer(customerID); ok {
+		return orders, true
+	}
+	orders, ok := t.Remote.GetByCustomer(customerID)
+	if ok {
+		t.Local.SetCustomerOrders(customerID, orders)
+	}
+	return orders, ok
+}
+
+func (t *TieredCache) SetCustomerOrders(customerID string, orders []*models.Order) {
+	t.Remote.SetCustomerOrders(customerID, orders)
+	t.Local.SetCustomerOrders(customerID, orders)
+}
+
+// Stats возвращает счетчики локального уровня, счетчики Redis - в поле Remote
+func (t *TieredCache) Stats() Stats {
+	stats := t.Local.Stats()
+	remote := t.Remote.Stats()
+	stats.Remote = &remote
+	return stats
+}
+
+// Run удаляет из локального уровня заказы, удаленные другими репликами, и истекшие заказы
+func (t *TieredCache) Run(ctx context.Context) {
+	go t.Local.Run(ctx)
+	t.Remote.Subscribe(ctx, t.Local.Delete)
+}
+
+func (t *TieredCache) tryLockWarmup() bool {
+	return t.Remote.tryLockWarmup()
+}
+
+func (t *TieredCache) Close() error {
+	return t.Remote.Close()
+}
@@ -1,5 +1,7 @@
 package postgres
 
+import "time"
+
 type PostgresConfig struct {
 	Host     string `env:"DB_HOST" envDefault:"db"`
 	Port     string `env:"DB_PORT" envDefault:"5432"`
@@ -7,4 +9,23 @@ type PostgresConfig struct {
 	Password string `env:"DB_PASSWORD,required"`
 	Name     string `env:"DB_NAME,required"`
 	SSLMode  string `env:"DB_SSLMODE" envDefault:"disable"`
+
+	Pool PoolConfig
+
+	// реплики для чтения в виде host или host:port, пользователь, пароль и база те же, что у primary
+	Replicas             []string      `env:"DB_REPLICAS" envSeparator:","`
+	ReplicaMaxLag        time.Duration `env:"DB_REPLICA_MAX_LAG" envDefault:"5s"`        // реплики с большим отставанием не используются
+	ReplicaCheckInterval time.Duration `env:"DB_REPLICA_CHECK_INTERVAL" envDefault:"5s"` // как часто проверять доступность и отставание реплик
+}
+
+// PoolConfig настройки пула соединений
+type PoolConfig struct {
+	MinConns          int32         `env:"DB_POOL_MIN_CONNS" envDefault:"2"`
+	MaxConns          int32         `env:"DB_POOL_MAX_CONNS" envDefault:"10"`
+	MaxConnLifetime   time.Duration `env:"DB_POOL_MAX_CONN_LIFETIME" envDefault:"1h"`
+	MaxConnIdleTime   time.Duration `env:"DB_POOL_MAX_CONN_IDLE_TIME" envDefault:"30m"`
+	HealthCheckPeriod time.Duration `env:"DB_POOL_HEALTH_CHECK_PERIOD" envDefault:"1m"`
+	// режим выполнения запросов: cache_statement, cache_describe, describe_exec, exec или simple_protocol.
+	// Через pgbouncer в режиме transaction кэш подготовленных запросов не работает, там нужен exec или simple_protocol
+	StatementCacheMode string `env:"DB_STATEMENT_CACHE_MODE" envDefault:"cache_statement"`
 }
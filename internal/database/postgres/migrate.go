@@ -1,19 +1,34 @@
 package postgres
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+// migrationsDir каталог с миграциями относительно рабочего каталога сервиса
+const migrationsDir = "migrations"
+
+var (
+	ErrMigrationsDirty    = errors.New("last migration failed, schema is dirty")
+	ErrMigrationsOutdated = errors.New("schema version differs from expected")
+)
+
 func RunMigrations(cfg *PostgresConfig) error {
 
 	// создадим мигратор
 	m, err := migrate.New(
-		"file://migrations",
+		"file://"+migrationsDir,
 		fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
 			cfg.User,
 			cfg.Password,
@@ -31,3 +46,48 @@ func RunMigrations(cfg *PostgresConfig) error {
 	}
 	return nil
 }
+
+// LatestMigrationVersion версия последней миграции в каталоге migrations - ее схема БД должна иметь после запуска
+func LatestMigrationVersion() (uint, error) {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return 0, fmt.Errorf("[LatestMigrationVersion| read dir]: %w", err)
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
+}
+
+// CheckMigrations проверяет, что схема БД имеет версию expected и последняя миграция прошла успешно
+func CheckMigrations(ctx context.Context, pool *pgxpool.Pool, expected uint) error {
+	var (
+		version uint
+		dirty   bool
+	)
+	err := pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("[CheckMigrations| no version, expected %d]: %w", expected, ErrMigrationsOutdated)
+	}
+	if err != nil {
+		return fmt.Errorf("[CheckMigrations| select version]: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("[CheckMigrations| version %d]: %w", version, ErrMigrationsDirty)
+	}
+	if version != expected {
+		return fmt.Errorf("[CheckMigrations| version %d, expected %d]: %w", version, expected, ErrMigrationsOutdated)
+	}
+	return nil
+}
@@ -5,27 +5,120 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewPostgresDB(ctx context.Context, cfg *PostgresConfig) (*pgx.Conn, error) {
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name, cfg.SSLMode)
-	conn, err := pgx.Connect(ctx, dsn)
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// TracerFunc создает tracer запросов для пула соединений с server: "primary" или адресом реплики
+type TracerFunc func(server string) pgx.QueryTracer
+
+func NewPostgresDB(ctx context.Context, cfg *PostgresConfig, tracer TracerFunc) (*pgxpool.Pool, error) {
+	pool, err := newPool(ctx, cfg, cfg.Host, cfg.Port, "primary", tracer)
 	if err != nil {
-		return nil, fmt.Errorf("[NewPostgresDB|connect] , %w", err)
+		return nil, fmt.Errorf("[NewPostgresDB|new pool] , %w", err)
 	}
 
-	if err := conn.Ping(ctx); err != nil {
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("[NewPostgresDB|ping] , %w", err)
 	}
 
-	return conn, nil
+	return pool, nil
 }
 
-func ClosePostgresDB(ctx context.Context, conn *pgx.Conn) error {
-	err := conn.Close(ctx)
+// newPool создает пул соединений с сервером host:port; соединения открываются в фоне
+func newPool(ctx context.Context, cfg *PostgresConfig, host, port, server string, tracer TracerFunc) (*pgxpool.Pool, error) {
+	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=%s",
+		cfg.User, cfg.Password, host, port, cfg.Name, cfg.SSLMode)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("[newPool|parse config] , %w", err)
+	}
+
+	err = applyPoolConfig(poolCfg, &cfg.Pool)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("[newPool|pool config] , %w", err)
+	}
+	if tracer != nil {
+		poolCfg.ConnConfig.Tracer = tracer(server)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("[newPool|connect] , %w", err)
+	}
+	return pool, nil
+}
+
+func applyPoolConfig(poolCfg *pgxpool.Config, cfg *PoolConfig) error {
+	mode, ok := queryExecModes[cfg.StatementCacheMode]
+	if !ok {
+		return fmt.Errorf("unknown statement cache mode %q", cfg.StatementCacheMode)
+	}
+	if cfg.MaxConns <= 0 || cfg.MinConns < 0 || cfg.MinConns > cfg.MaxConns {
+		return fmt.Errorf("invalid pool size: min %d, max %d", cfg.MinConns, cfg.MaxConns)
 	}
+
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	poolCfg.ConnConfig.DefaultQueryExecMode = mode
 	return nil
 }
+
+func ClosePostgresDB(pool *pgxpool.Pool) {
+	pool.Close()
+}
+
+// PoolStats состояние пула соединений
+// @Description Соединения пула и счетчики их выдачи
+type PoolStats struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+
+	AcquireCount         int64 `json:"acquire_count"`
+	EmptyAcquireCount    int64 `json:"empty_acquire_count"` // сколько раз пришлось ждать свободное соединение
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+	AcquireDurationMs    int64 `json:"acquire_duration_ms"` // суммарное время ожидания соединений
+
+	NewConnsCount           int64 `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64 `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64 `json:"max_idle_destroy_count"`
+}
+
+func Stats(pool *pgxpool.Pool) PoolStats {
+	s := pool.Stat()
+	return PoolStats{
+		MaxConns:                s.MaxConns(),
+		TotalConns:              s.TotalConns(),
+		IdleConns:               s.IdleConns(),
+		AcquiredConns:           s.AcquiredConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		AcquireCount:            s.AcquireCount(),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		AcquireDurationMs:       s.AcquireDuration().Milliseconds(),
+		NewConnsCount:           s.NewConnsCount(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+	}
+}
+
+// DBStats состояние соединений с primary и репликами
+// @Description Пул соединений с primary и состояние реплик для чтения
+type DBStats struct {
+	Primary  PoolStats      `json:"primary"`
+	Replicas []ReplicaStats `json:"replicas,omitempty"`
+}
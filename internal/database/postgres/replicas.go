@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLagQuery отставание реплики в секундах. Если реплика применила все полученные изменения,
+// отставание нулевое, даже если на primary давно не было записей
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// Replicas реплики для чтения. Реплика используется, пока она отвечает и отстает от primary не больше чем на maxLag
+type Replicas struct {
+	replicas      []*replica
+	maxLag        time.Duration
+	checkInterval time.Duration
+	next          atomic.Uint64
+}
+
+type replica struct {
+	addr    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+	lag     atomic.Int64 // последнее измеренное отставание в наносекундах
+}
+
+// ReplicaStats состояние реплики
+// @Description Адрес реплики, ее доступность, отставание от primary и пул соединений
+type ReplicaStats struct {
+	Addr    string    `json:"addr"`
+	Healthy bool      `json:"healthy"`
+	LagMs   int64     `json:"lag_ms"`
+	Pool    PoolStats `json:"pool"`
+}
+
+// NewReplicas создает пулы соединений с репликами из cfg.Replicas и сразу проверяет их состояние.
+// Без реплик возвращает nil
+func NewReplicas(ctx context.Context, cfg *PostgresConfig, tracer TracerFunc) (*Replicas, error) {
+	if len(cfg.Replicas) == 0 {
+		return nil, nil
+	}
+
+	rs := &Replicas{
+		maxLag:        cfg.ReplicaMaxLag,
+		checkInterval: cfg.ReplicaCheckInterval,
+	}
+	for _, addr := range cfg.Replicas {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			host, port = addr, cfg.Port
+		}
+
+		pool, err := newPool(ctx, cfg, host, port, addr, tracer)
+		if err != nil {
+			rs.Close()
+			return nil, fmt.Errorf("[NewReplicas|new pool %s] , %w", addr, err)
+		}
+		rs.replicas = append(rs.replicas, &replica{addr: addr, pool: pool})
+	}
+
+	rs.check(ctx)
+	return rs, nil
+}
+
+// Reader возвращает пул одной из доступных реплик по очереди или nil, если доступных нет
+func (rs *Replicas) Reader() *pgxpool.Pool {
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := rs.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return nil
+}
+
+// MaxStaleness насколько данные, прочитанные с реплики, могут отставать от primary:
+// допустимое отставание плюс время до следующей проверки
+func (rs *Replicas) MaxStaleness() time.Duration {
+	return rs.maxLag + rs.checkInterval
+}
+
+// Run проверяет реплики каждые checkInterval, пока не отменен ctx
+func (rs *Replicas) Run(ctx context.Context) {
+	if rs.checkInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(rs.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rs.check(ctx)
+		}
+	}
+}
+
+func (rs *Replicas) check(ctx context.Context) {
+	for _, r := range rs.replicas {
+		healthy := r.check(ctx, rs.maxLag, rs.checkInterval)
+		if was := r.healthy.Swap(healthy); was != healthy {
+			slog.Warn("Replica state changed", "addr", r.addr, "healthy", healthy, "lag", time.Duration(r.lag.Load()))
+		}
+	}
+}
+
+// check измеряет отставание реплики; реплика здорова, если ответила за timeout и отстает не больше maxLag
+func (r *replica) check(ctx context.Context, maxLag, timeout time.Duration) bool {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var lagSeconds float64
+	err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&lagSeconds)
+	if err != nil {
+		slog.Debug("Replica check failed", "addr", r.addr, "error", err)
+		return false
+	}
+
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	r.lag.Store(int64(lag))
+	return lag <= maxLag
+}
+
+func (rs *Replicas) Stats() []ReplicaStats {
+	stats := make([]ReplicaStats, 0, len(rs.replicas))
+	for _, r := range rs.replicas {
+		stats = append(stats, ReplicaStats{
+			Addr:    r.addr,
+			Healthy: r.healthy.Load(),
+			LagMs:   time.Duration(r.lag.Load()).Milliseconds(),
+			Pool:    Stats(r.pool),
+		})
+	}
+	return stats
+}
+
+func (rs *Replicas) Close() {
+	for _, r := range rs.replicas {
+		r.pool.Close()
+	}
+}
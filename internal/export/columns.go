@@ -0,0 +1,93 @@
+package export
+
+import (
+	"github.com/orders_api/internal/models"
+)
+
+// columnKind тип значений колонки плоской выгрузки
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindInt
+	kindTime // миллисекунды Unix в UTC
+)
+
+// column колонка плоской выгрузки: строка выгрузки - позиция заказа вместе с полями заказа, платежа и доставки
+type column struct {
+	name string
+	kind columnKind
+	str  func(o *models.Order, it *models.Item) string
+	num  func(o *models.Order, it *models.Item) int64
+}
+
+func stringColumn(name string, f func(o *models.Order, it *models.Item) string) column {
+	return column{name: name, kind: kindString, str: f}
+}
+
+func intColumn(name string, f func(o *models.Order, it *models.Item) int64) column {
+	return column{name: name, kind: kindInt, num: f}
+}
+
+// columns колонки CSV и Parquet выгрузок в порядке вывода
+var columns = []column{
+	stringColumn("order_uid", func(o *models.Order, _ *models.Item) string { return o.OrderUID.String() }),
+	stringColumn("track_number", func(o *models.Order, _ *models.Item) string { return o.TrackNumber }),
+	stringColumn("entry", func(o *models.Order, _ *models.Item) string { return o.Entry }),
+	stringColumn("locale", func(o *models.Order, _ *models.Item) string { return o.Locale }),
+	stringColumn("internal_signature", func(o *models.Order, _ *models.Item) string { return o.InternalSignature }),
+	stringColumn("customer_id", func(o *models.Order, _ *models.Item) string { return o.CustomerID }),
+	stringColumn("delivery_service", func(o *models.Order, _ *models.Item) string { return o.DeliveryService }),
+	stringColumn("shardkey", func(o *models.Order, _ *models.Item) string { return o.Shardkey }),
+	intColumn("sm_id", func(o *models.Order, _ *models.Item) int64 { return int64(o.SmID) }),
+	{name: "date_created", kind: kindTime, num: func(o *models.Order, _ *models.Item) int64 { return o.DateCreated.UnixMilli() }},
+	stringColumn("oof_shard", func(o *models.Order, _ *models.Item) string { return o.OofShard }),
+	stringColumn("status", func(o *models.Order, _ *models.Item) string { return string(o.Status) }),
+	intColumn("version", func(o *models.Order, _ *models.Item) int64 { return o.Version }),
+
+	stringColumn("payment_transaction", func(o *models.Order, _ *models.Item) string { return o.Payment.Transaction.String() }),
+	stringColumn("payment_request_id", func(o *models.Order, _ *models.Item) string { return o.Payment.RequestID }),
+	stringColumn("payment_currency", func(o *models.Order, _ *models.Item) string { return o.Payment.Currency }),
+	stringColumn("payment_provider", func(o *models.Order, _ *models.Item) string { return o.Payment.Provider }),
+	intColumn("payment_amount", func(o *models.Order, _ *models.Item) int64 { return int64(o.Payment.Amount) }),
+	intColumn("payment_dt", func(o *models.Order, _ *models.Item) int64 { return o.Payment.PaymentDt }),
+	stringColumn("payment_bank", func(o *models.Order, _ *models.Item) string { return o.Payment.Bank }),
+	intColumn("payment_delivery_cost", func(o *models.Order, _ *models.Item) int64 { return int64(o.Payment.DeliveryCost) }),
+	intColumn("payment_goods_total", func(o *models.Order, _ *models.Item) int64 { return int64(o.Payment.GoodsTotal) }),
+	intColumn("payment_custom_fee", func(o *models.Order, _ *models.Item) int64 { return int64(o.Payment.CustomFee) }),
+
+	stringColumn("delivery_name", func(o *models.Order, _ *models.Item) string { return o.Delivery.Name }),
+	stringColumn("delivery_phone", func(o *models.Order, _ *models.Item) string { return o.Delivery.Phone }),
+	stringColumn("delivery_zip", func(o *models.Order, _ *models.Item) string { return o.Delivery.Zip }),
+	stringColumn("delivery_city", func(o *models.Order, _ *models.Item) string { return o.Delivery.City }),
+	stringColumn("delivery_address", func(o *models.Order, _ *models.Item) string { return o.Delivery.Address }),
+	stringColumn("delivery_region", func(o *models.Order, _ *models.Item) string { return o.Delivery.Region }),
+	stringColumn("delivery_email", func(o *models.Order, _ *models.Item) string { return o.Delivery.Email }),
+
+	intColumn("item_chrt_id", func(_ *models.Order, it *models.Item) int64 { return int64(it.ChrtID) }),
+	intColumn("item_price", func(_ *models.Order, it *models.Item) int64 { return int64(it.Price) }),
+	stringColumn("item_rid", func(_ *models.Order, it *models.Item) string { return it.Rid }),
+	stringColumn("item_name", func(_ *models.Order, it *models.Item) string { return it.Name }),
+	intColumn("item_sale", func(_ *models.Order, it *models.Item) int64 { return int64(it.Sale) }),
+	stringColumn("item_size", func(_ *models.Order, it *models.Item) string { return it.Size }),
+	intColumn("item_total_price", func(_ *models.Order, it *models.Item) int64 { return int64(it.TotalPrice) }),
+	intColumn("item_nm_id", func(_ *models.Order, it *models.Item) int64 { return int64(it.NmID) }),
+	stringColumn("item_brand", func(_ *models.Order, it *models.Item) string { return it.Brand }),
+	intColumn("item_status", func(_ *models.Order, it *models.Item) int64 { return int64(it.Status) }),
+}
+
+// noItem позиция строки заказа без позиций: колонки позиции заполняются нулевыми значениями
+var noItem = &models.Item{}
+
+// eachRow вызывает fn для каждой строки плоской выгрузки заказа: по строке на позицию
+func eachRow(o *models.Order, fn func(it *models.Item) error) error {
+	if len(o.Items) == 0 {
+		return fn(noItem)
+	}
+	for i := range o.Items {
+		if err := fn(&o.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
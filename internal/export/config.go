@@ -0,0 +1,7 @@
+package export
+
+// Config ограничения выгрузки заказов
+type Config struct {
+	BatchSize   int `env:"EXPORT_BATCH_SIZE" envDefault:"500"` // сколько заказов читается из курсора за раз
+	MaxRequests int `env:"EXPORT_MAX_REQUESTS" envDefault:"2"` // сколько выгрузок выполняется одновременно
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/orders_api/internal/models"
+)
+
+// csvWriter CSV выгрузка: заголовок с именами колонок и строка на каждую позицию заказа
+type csvWriter struct {
+	w         *csv.Writer
+	record    []string
+	hasHeader bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(columns)),
+	}
+}
+
+func (c *csvWriter) Write(orders []*models.Order) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		err := eachRow(o, func(it *models.Item) error {
+			for i, col := range columns {
+				c.record[i] = formatCSV(col, o, it)
+			}
+			return c.w.Write(c.record)
+		})
+		if err != nil {
+			return fmt.Errorf("[csvWriter.Write| write row]: %w", err)
+		}
+	}
+
+	// каждая порция сразу уходит клиенту
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("[csvWriter.Write| flush]: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	// пустая выгрузка тоже содержит заголовок
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("[csvWriter.Close| flush]: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.hasHeader {
+		return nil
+	}
+	for i, col := range columns {
+		c.record[i] = col.name
+	}
+	if err := c.w.Write(c.record); err != nil {
+		return fmt.Errorf("[csvWriter| write header]: %w", err)
+	}
+	c.hasHeader = true
+	return nil
+}
+
+func formatCSV(col column, o *models.Order, it *models.Item) string {
+	switch col.kind {
+	case kindInt:
+		return strconv.FormatInt(col.num(o, it), 10)
+	case kindTime:
+		return time.UnixMilli(col.num(o, it)).UTC().Format(time.RFC3339Nano)
+	default:
+		return col.str(o, it)
+	}
+}
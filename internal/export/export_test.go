@@ -0,0 +1,257 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrders() []*models.Order {
+	created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	return []*models.Order{
+		{
+			OrderUID:    uuid.Must(uuid.FromString("b563feb7-b2b8-4b6c-9f5d-000000000001")),
+			TrackNumber: "WBILMTESTTRACK",
+			DateCreated: created,
+			Payment:     models.Payment{Amount: 1817, Currency: "USD"},
+			Delivery:    models.Delivery{City: "Kiryat Mozkin"},
+			Items: []models.Item{
+				{ChrtID: 9934930, Name: "Mascaras", Price: 453},
+				{ChrtID: 9934931, Name: "Lipstick, red", Price: 120},
+			},
+		},
+		{
+			OrderUID:    uuid.Must(uuid.FromString("b563feb7-b2b8-4b6c-9f5d-000000000002")),
+			TrackNumber: "WBILMTESTTRACK2",
+			DateCreated: created,
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("parquet")
+	require.NoError(t, err)
+	assert.Equal(t, FormatParquet, format)
+
+	format, err = ParseMediaType("application/x-ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = ParseMediaType("")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestCSVWriter_RowPerItem(t *testing.T) {
+	var out bytes.Buffer
+	w, err := NewWriter(FormatCSV, &out)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(testOrders()))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	// заголовок, две позиции первого заказа и строка заказа без позиций
+	require.Len(t, records, 4)
+
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[2][i]
+	}
+	assert.Equal(t, "WBILMTESTTRACK", row["track_number"])
+	assert.Equal(t, "2021-11-26T06:22:19Z", row["date_created"])
+	assert.Equal(t, "1817", row["payment_amount"])
+	assert.Equal(t, "Kiryat Mozkin", row["delivery_city"])
+	assert.Equal(t, "Lipstick, red", row["item_name"])
+	assert.Equal(t, "0", records[3][len(records[3])-1])
+}
+
+func TestCSVWriter_EmptyHasHeader(t *testing.T) {
+	var out bytes.Buffer
+	w := newCSVWriter(&out)
+	require.NoError(t, w.Close())
+	assert.True(t, strings.HasPrefix(out.String(), "order_uid,track_number,"))
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newNDJSONWriter(&out)
+	require.NoError(t, w.Write(testOrders()))
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &order))
+	assert.Len(t, order.Items, 2)
+}
+
+func TestThriftWriter(t *testing.T) {
+	var tw thriftWriter
+	tw.beginStruct()
+	tw.i32Field(1, 1)
+	tw.i64Field(20, -1)
+	tw.structField(21)
+	tw.stringField(1, "ab")
+	tw.endStruct()
+	tw.stringField(22, "c")
+	tw.endStruct()
+
+	expected := []byte{
+		0x15, 0x02, // поле 1 i32 = 1
+		0x06, 0x28, 0x01, // поле 20 i64 = -1: номер поля отдельно
+		0x1c,                    // поле 21 struct
+		0x18, 0x02, 'a', 'b', 0, // поле 1 binary, конец вложенной структуры
+		0x18, 0x01, 'c', 0, // поле 22 binary после вложенной структуры
+	}
+	assert.Equal(t, expected, tw.buf)
+}
+
+func TestParquetWriter_Layout(t *testing.T) {
+	var out bytes.Buffer
+	w := newParquetWriter(&out)
+	require.NoError(t, w.Write(testOrders()))
+	require.NoError(t, w.Close())
+
+	data := out.Bytes()
+	require.True(t, bytes.HasPrefix(data, []byte(parquetMagic)))
+	require.True(t, bytes.HasSuffix(data, []byte(parquetMagic)))
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-footerLen : len(data)-8]
+	for _, col := range columns {
+		assert.Contains(t, string(footer), col.name)
+	}
+
+	// одна группа из трех строк: первая колонка начинается сразу после PAR1 и содержит order_uid каждой строки
+	require.Len(t, w.groups, 1)
+	assert.EqualValues(t, 3, w.groups[0].rows)
+	assert.EqualValues(t, len(parquetMagic), w.groups[0].chunks[0].offset)
+	first := data[w.groups[0].chunks[0].offset:w.groups[0].chunks[1].offset]
+	assert.Equal(t, 2, bytes.Count(first, []byte("b563feb7-b2b8-4b6c-9f5d-000000000001")))
+	assert.Equal(t, 1, bytes.Count(first, []byte("b563feb7-b2b8-4b6c-9f5d-000000000002")))
+}
+
+// readParquet читает файл сторонней Parquet библиотекой и возвращает строки как значения колонок по именам
+func readParquet(t *testing.T, data []byte) (*parquet.File, []map[string]parquet.Value) {
+	t.Helper()
+
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var rows []map[string]parquet.Value
+	for _, group := range f.RowGroups() {
+		reader := group.Rows()
+		buf := make([]parquet.Row, 16)
+		for {
+			n, err := reader.ReadRows(buf)
+			for _, row := range buf[:n] {
+				values := make(map[string]parquet.Value, len(row))
+				for _, v := range row {
+					values[f.Root().Columns()[v.Column()].Name()] = v
+				}
+				rows = append(rows, values)
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		require.NoError(t, reader.Close())
+	}
+	return f, rows
+}
+
+func TestParquetWriter_ReadBack(t *testing.T) {
+	orders := testOrders()
+
+	var out bytes.Buffer
+	w := newParquetWriter(&out)
+	require.NoError(t, w.Write(orders))
+	require.NoError(t, w.Close())
+
+	f, rows := readParquet(t, out.Bytes())
+	assert.EqualValues(t, 3, f.NumRows())
+
+	// колонки в порядке выгрузки, с логическими типами строк и времени
+	fileColumns := f.Root().Columns()
+	require.Len(t, fileColumns, len(columns))
+	for i, col := range columns {
+		assert.Equal(t, col.name, fileColumns[i].Name())
+		assert.True(t, fileColumns[i].Required(), col.name)
+		switch col.kind {
+		case kindString:
+			assert.Equal(t, parquet.ByteArray, fileColumns[i].Type().Kind(), col.name)
+			assert.NotNil(t, fileColumns[i].Type().LogicalType().UTF8, col.name)
+		case kindTime:
+			assert.Equal(t, parquet.Int64, fileColumns[i].Type().Kind(), col.name)
+			assert.NotNil(t, fileColumns[i].Type().LogicalType().Timestamp, col.name)
+		default:
+			assert.Equal(t, parquet.Int64, fileColumns[i].Type().Kind(), col.name)
+		}
+	}
+
+	// строка на каждую позицию, заказ без позиций - одна строка с пустыми колонками позиции
+	expected := []struct {
+		order *models.Order
+		item  *models.Item
+	}{
+		{orders[0], &orders[0].Items[0]},
+		{orders[0], &orders[0].Items[1]},
+		{orders[1], noItem},
+	}
+	require.Len(t, rows, len(expected))
+	for i, exp := range expected {
+		for _, col := range columns {
+			v := rows[i][col.name]
+			if col.kind == kindString {
+				assert.Equal(t, col.str(exp.order, exp.item), string(v.ByteArray()), "row %d %s", i, col.name)
+				continue
+			}
+			assert.Equal(t, col.num(exp.order, exp.item), v.Int64(), "row %d %s", i, col.name)
+		}
+	}
+}
+
+func TestParquetWriter_ReadBackRowGroups(t *testing.T) {
+	// заказы с длинными названиями позиций не помещаются в одну группу строк
+	name := strings.Repeat("x", 64<<10)
+	orders := make([]*models.Order, 200)
+	for i := range orders {
+		orders[i] = &models.Order{
+			OrderUID: uuid.Must(uuid.NewV4()),
+			Items:    []models.Item{{ChrtID: i, Name: name}},
+		}
+	}
+
+	var out bytes.Buffer
+	w := newParquetWriter(&out)
+	for i := 0; i < len(orders); i += 50 {
+		require.NoError(t, w.Write(orders[i:i+50]))
+	}
+	require.NoError(t, w.Close())
+
+	f, rows := readParquet(t, out.Bytes())
+	assert.Greater(t, len(f.RowGroups()), 1)
+	require.Len(t, rows, len(orders))
+	for i, row := range rows {
+		assert.Equal(t, orders[i].OrderUID.String(), string(row["order_uid"].ByteArray()))
+		assert.EqualValues(t, i, row["item_chrt_id"].Int64())
+		assert.Len(t, row["item_name"].ByteArray(), len(name))
+	}
+}
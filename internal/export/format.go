@@ -0,0 +1,91 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/orders_api/internal/models"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Format формат выгрузки заказов
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// mediaTypes форматы в порядке предпочтения: первый выбирается, если клиент принимает любой
+var mediaTypes = []struct {
+	format    Format
+	mediaType string
+}{
+	{FormatCSV, "text/csv"},
+	{FormatNDJSON, "application/x-ndjson"},
+	{FormatParquet, "application/vnd.apache.parquet"},
+}
+
+// MediaTypes типы содержимого всех форматов, первым идет формат по умолчанию
+func MediaTypes() []string {
+	types := make([]string, 0, len(mediaTypes))
+	for _, t := range mediaTypes {
+		types = append(types, t.mediaType)
+	}
+	return types
+}
+
+// ParseFormat формат по значению параметра format
+func ParseFormat(s string) (Format, error) {
+	for _, t := range mediaTypes {
+		if string(t.format) == s {
+			return t.format, nil
+		}
+	}
+	return "", fmt.Errorf("[ParseFormat| format %q]: %w", s, ErrUnsupportedFormat)
+}
+
+// ParseMediaType формат по типу содержимого из списка MediaTypes
+func ParseMediaType(mediaType string) (Format, error) {
+	for _, t := range mediaTypes {
+		if t.mediaType == mediaType {
+			return t.format, nil
+		}
+	}
+	return "", fmt.Errorf("[ParseMediaType| media type %q]: %w", mediaType, ErrUnsupportedFormat)
+}
+
+// ContentType тип содержимого выгрузки в формате f
+func (f Format) ContentType() string {
+	for _, t := range mediaTypes {
+		if t.format == f {
+			return t.mediaType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// Writer записывает заказы в выгрузку по мере чтения
+type Writer interface {
+	// Write дописывает заказы в выгрузку
+	Write(orders []*models.Order) error
+	// Close завершает выгрузку, сам поток при этом не закрывается
+	Close() error
+}
+
+// NewWriter создает запись выгрузки в формате format в поток w
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatParquet:
+		return newParquetWriter(w), nil
+	default:
+		return nil, fmt.Errorf("[NewWriter| format %q]: %w", format, ErrUnsupportedFormat)
+	}
+}
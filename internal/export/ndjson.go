@@ -0,0 +1,41 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/orders_api/internal/models"
+)
+
+// ndjsonWriter NDJSON выгрузка: заказ целиком, вместе с позициями, на строку
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (n *ndjsonWriter) Write(orders []*models.Order) error {
+	for _, o := range orders {
+		// Encode завершает каждый заказ переводом строки
+		if err := n.enc.Encode(o); err != nil {
+			return fmt.Errorf("[ndjsonWriter.Write| encode order]: %w", err)
+		}
+	}
+	if err := n.buf.Flush(); err != nil {
+		return fmt.Errorf("[ndjsonWriter.Write| flush]: %w", err)
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	if err := n.buf.Flush(); err != nil {
+		return fmt.Errorf("[ndjsonWriter.Close| flush]: %w", err)
+	}
+	return nil
+}
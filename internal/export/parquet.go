@@ -0,0 +1,256 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/orders_api/internal/models"
+)
+
+// parquetMagic начало и конец Parquet файла
+const parquetMagic = "PAR1"
+
+// parquetRowGroupBytes сколько данных копится в памяти до записи группы строк
+const parquetRowGroupBytes = 8 << 20
+
+// значения перечислений из parquet.thrift
+const (
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetRequired = 0
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetDataPage = 0
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetUncompressed = 0
+)
+
+// parquetWriter Parquet выгрузка с колонками columns: все колонки обязательные, значения в кодировке PLAIN без сжатия.
+// Строки копятся в памяти группами до parquetRowGroupBytes, каждая колонка группы пишется одной страницей,
+// метаданные групп записываются в конец файла при Close
+type parquetWriter struct {
+	w      io.Writer
+	offset int64 // сколько байт файла уже записано
+
+	values  []bytes.Buffer // значения колонок текущей группы
+	rows    int64          // строк в текущей группе
+	total   int64
+	groups  []parquetRowGroup
+	started bool
+
+	meta thriftWriter
+}
+
+// parquetRowGroup метаданные записанной группы строк
+type parquetRowGroup struct {
+	chunks []parquetChunk
+	rows   int64
+	size   int64
+}
+
+// parquetChunk метаданные колонки в группе строк
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+func newParquetWriter(w io.Writer) *parquetWriter {
+	return &parquetWriter{
+		w:      w,
+		values: make([]bytes.Buffer, len(columns)),
+	}
+}
+
+func (p *parquetWriter) Write(orders []*models.Order) error {
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	var num [8]byte
+	for _, o := range orders {
+		_ = eachRow(o, func(it *models.Item) error {
+			for i, col := range columns {
+				v := &p.values[i]
+				if col.kind == kindString {
+					s := col.str(o, it)
+					binary.LittleEndian.PutUint32(num[:4], uint32(len(s)))
+					v.Write(num[:4])
+					v.WriteString(s)
+					continue
+				}
+				binary.LittleEndian.PutUint64(num[:], uint64(col.num(o, it)))
+				v.Write(num[:])
+			}
+			p.rows++
+			return nil
+		})
+	}
+
+	if p.buffered() >= parquetRowGroupBytes {
+		return p.flushRowGroup()
+	}
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+	if err := p.flushRowGroup(); err != nil {
+		return err
+	}
+
+	p.writeFileMetaData()
+	footer := binary.LittleEndian.AppendUint32(p.meta.buf, uint32(len(p.meta.buf)))
+	footer = append(footer, parquetMagic...)
+	if err := p.write(footer); err != nil {
+		return fmt.Errorf("[parquetWriter.Close| write footer]: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetWriter) start() error {
+	if p.started {
+		return nil
+	}
+	p.started = true
+	if err := p.write([]byte(parquetMagic)); err != nil {
+		return fmt.Errorf("[parquetWriter| write magic]: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetWriter) buffered() int {
+	var n int
+	for i := range p.values {
+		n += p.values[i].Len()
+	}
+	return n
+}
+
+// flushRowGroup записывает накопленные строки группой: по странице данных на колонку
+func (p *parquetWriter) flushRowGroup() error {
+	if p.rows == 0 {
+		return nil
+	}
+
+	group := parquetRowGroup{chunks: make([]parquetChunk, len(columns)), rows: p.rows}
+	for i := range columns {
+		data := p.values[i].Bytes()
+		p.writePageHeader(len(data))
+
+		chunk := parquetChunk{offset: p.offset, size: int64(len(p.meta.buf) + len(data))}
+		if err := p.write(p.meta.buf); err != nil {
+			return fmt.Errorf("[parquetWriter| write page header %s]: %w", columns[i].name, err)
+		}
+		if err := p.write(data); err != nil {
+			return fmt.Errorf("[parquetWriter| write page %s]: %w", columns[i].name, err)
+		}
+
+		group.chunks[i] = chunk
+		group.size += chunk.size
+		p.values[i].Reset()
+	}
+
+	p.groups = append(p.groups, group)
+	p.total += p.rows
+	p.rows = 0
+	return nil
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// writePageHeader кодирует PageHeader страницы данных размером size в p.meta
+func (p *parquetWriter) writePageHeader(size int) {
+	t := &p.meta
+	t.reset()
+	t.beginStruct()
+	t.i32Field(1, parquetDataPage)
+	t.i32Field(2, int32(size))
+	t.i32Field(3, int32(size))
+	t.structField(5)
+	t.i32Field(1, int32(p.rows))
+	t.i32Field(2, parquetPlain)
+	t.i32Field(3, parquetRLE)
+	t.i32Field(4, parquetRLE)
+	t.endStruct()
+	t.endStruct()
+}
+
+// writeFileMetaData кодирует FileMetaData файла в p.meta
+func (p *parquetWriter) writeFileMetaData() {
+	t := &p.meta
+	t.reset()
+	t.beginStruct()
+	t.i32Field(1, 1)
+
+	// схема: корневой элемент и плоский список колонок
+	t.listField(2, thriftStruct, len(columns)+1)
+	t.beginStruct()
+	t.stringField(4, "order_row")
+	t.i32Field(5, int32(len(columns)))
+	t.endStruct()
+	for _, col := range columns {
+		t.beginStruct()
+		t.i32Field(1, parquetType(col))
+		t.i32Field(3, parquetRequired)
+		t.stringField(4, col.name)
+		switch col.kind {
+		case kindString:
+			t.i32Field(6, parquetUTF8)
+		case kindTime:
+			t.i32Field(6, parquetTimestampMillis)
+		}
+		t.endStruct()
+	}
+
+	t.i64Field(3, p.total)
+
+	t.listField(4, thriftStruct, len(p.groups))
+	for _, g := range p.groups {
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(g.chunks))
+		for i, c := range g.chunks {
+			t.beginStruct()
+			t.i64Field(2, c.offset)
+			t.structField(3)
+			t.i32Field(1, parquetType(columns[i]))
+			t.listField(2, thriftI32, 1)
+			t.varint(parquetPlain)
+			t.listField(3, thriftBinary, 1)
+			t.string(columns[i].name)
+			t.i32Field(4, parquetUncompressed)
+			t.i64Field(5, g.rows)
+			t.i64Field(6, c.size)
+			t.i64Field(7, c.size)
+			t.i64Field(9, c.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64Field(2, g.size)
+		t.i64Field(3, g.rows)
+		t.endStruct()
+	}
+
+	t.stringField(6, "orders_api")
+	t.endStruct()
+}
+
+func parquetType(col column) int32 {
+	if col.kind == kindString {
+		return parquetByteArray
+	}
+	return parquetInt64
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/binary"
+)
+
+// типы полей thrift compact protocol, которые нужны метаданным Parquet
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter кодирует структуры thrift compact protocol: так записываются заголовки страниц и метаданные Parquet файла
+type thriftWriter struct {
+	buf []byte
+
+	// номер последнего поля текущей структуры и вложенных в нее
+	last  int16
+	stack []int16
+}
+
+func (t *thriftWriter) reset() {
+	t.buf = t.buf[:0]
+	t.last = 0
+	t.stack = t.stack[:0]
+}
+
+// field заголовок поля: разница с номером предыдущего поля до 15 пишется в один байт вместе с типом
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+// varint число в zigzag кодировке
+func (t *thriftWriter) varint(v int64) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(v<<1^v>>63))
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) stringField(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.string(s)
+}
+
+func (t *thriftWriter) string(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// listField заголовок списка из n элементов типа elem; элементы пишутся следом
+func (t *thriftWriter) listField(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elem)
+	t.buf = binary.AppendUvarint(t.buf, uint64(n))
+}
+
+// structField начинает вложенную структуру в поле id
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.beginStruct()
+}
+
+// beginStruct начинает структуру: верхнего уровня или элемент списка
+func (t *thriftWriter) beginStruct() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
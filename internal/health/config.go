@@ -0,0 +1,8 @@
+package health
+
+import "time"
+
+// Config настройки проверок готовности
+type Config struct {
+	CheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"` // сколько ждать ответа одной зависимости
+}
@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// статусы проверок и сервиса в целом
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var ErrNotReady = errors.New("not ready yet")
+
+// Check проверка одной зависимости сервиса; nil - зависимость доступна
+type Check func(ctx context.Context) error
+
+// Result результат одной проверки
+// @Description Статус проверки зависимости, время ее выполнения и ошибка, если проверка не прошла
+type Result struct {
+	Status    string  `json:"status" example:"ok"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report результаты всех проверок
+// @Description Общий статус готовности сервиса и результаты проверок по зависимостям
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// OK все проверки прошли
+func (r *Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker выполняет проверки зависимостей для /readyz
+type Checker struct {
+	timeout time.Duration
+	checks  []namedCheck
+}
+
+// NewChecker создает Checker; timeout ограничивает время каждой проверки
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add добавляет проверку name. Проверки добавляются при старте сервиса, до первого Run
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run выполняет все проверки параллельно
+func (c *Checker) Run(ctx context.Context) *Report {
+	results := make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, nc.check)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+	for i, nc := range c.checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	result := Result{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Flag проверка этапа запуска, который завершается один раз, например прогрева кэша
+type Flag struct {
+	done atomic.Bool
+}
+
+// Done отмечает этап завершенным
+func (f *Flag) Done() {
+	f.done.Store(true)
+}
+
+func (f *Flag) Check(context.Context) error {
+	if !f.done.Load() {
+		return ErrNotReady
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package ingest
+
+// Config ограничения массового импорта заказов
+type Config struct {
+	MaxBytes       int64 `env:"BULK_MAX_BYTES" envDefault:"1073741824"`   // максимальный размер тела запроса, 0 - без ограничения
+	BatchSize      int   `env:"BULK_BATCH_SIZE" envDefault:"500"`         // сколько заказов записывается одной транзакцией
+	Workers        int   `env:"BULK_WORKERS" envDefault:"4"`              // сколько пачек одного запроса записывается параллельно
+	MaxRequests    int   `env:"BULK_MAX_REQUESTS" envDefault:"2"`         // сколько импортов выполняется одновременно
+	ReportMaxLines int   `env:"BULK_REPORT_MAX_LINES" envDefault:"10000"` // сколько строк попадает в отчет, остальные только в итогах; 0 - без ограничения
+}
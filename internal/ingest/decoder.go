@@ -0,0 +1,200 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/orders_api/internal/models"
+)
+
+// maxLineBytes ограничение на размер одной строки NDJSON, чтобы одна строка не заняла всю память
+const maxLineBytes = 1 << 20
+
+var (
+	ErrBodyTooLarge = errors.New("request body is too large")
+	ErrLineTooLong  = errors.New("line is too long")
+	ErrInvalidJSON  = errors.New("invalid JSON")
+)
+
+// LineError ошибка разбора одного заказа; разбор продолжается со следующего
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder читает заказы из потока по одному.
+// Next возвращает номер строки (элемента массива) и заказ, *LineError для заказа, который не удалось разобрать,
+// io.EOF в конце потока; остальные ошибки означают, что поток дальше читать нельзя
+type Decoder interface {
+	Next() (int, *models.Order, error)
+}
+
+// NewDecoder выбирает формат по первому значащему символу: '[' - JSON массив заказов, иначе NDJSON
+func NewDecoder(r io.Reader) (Decoder, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return &ndjsonDecoder{r: br}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[NewDecoder| read]: %w", err)
+		}
+		if isSpace(b) {
+			continue
+		}
+		br.UnreadByte()
+		if b != '[' {
+			return &ndjsonDecoder{r: br}, nil
+		}
+
+		// открывающую скобку читает сам json.Decoder, чтобы дальше разбирать элементы массива
+		dec := json.NewDecoder(br)
+		_, err = dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("[NewDecoder| open array]: %w", err)
+		}
+		return &arrayDecoder{dec: dec}, nil
+	}
+}
+
+type ndjsonDecoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (d *ndjsonDecoder) Next() (int, *models.Order, error) {
+	for {
+		data, err := d.readLine()
+		if err != nil && !errors.Is(err, ErrLineTooLong) {
+			return 0, nil, err
+		}
+		d.line++
+		if err != nil {
+			return d.line, nil, &LineError{Line: d.line, Err: err}
+		}
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var order models.Order
+		err = json.Unmarshal(data, &order)
+		if err != nil {
+			return d.line, nil, &LineError{Line: d.line, Err: fmt.Errorf("%w: %w", ErrInvalidJSON, err)}
+		}
+		return d.line, &order, nil
+	}
+}
+
+// readLine читает строку без '\n'. Слишком длинная строка пропускается целиком и возвращается ErrLineTooLong
+func (d *ndjsonDecoder) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxLineBytes {
+			line = nil
+			for errors.Is(err, bufio.ErrBufferFull) {
+				_, err = d.r.ReadSlice('\n')
+			}
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			return nil, ErrLineTooLong
+		}
+		line = append(line, chunk...)
+
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == io.EOF && len(line) > 0:
+			return line, nil
+		case err != nil:
+			return nil, err
+		}
+		return line[:len(line)-1], nil
+	}
+}
+
+type arrayDecoder struct {
+	dec   *json.Decoder
+	index int
+	done  bool
+}
+
+func (d *arrayDecoder) Next() (int, *models.Order, error) {
+	if d.done || !d.dec.More() {
+		if !d.done {
+			// закрывающая скобка массива
+			d.done = true
+			_, err := d.dec.Token()
+			if err != nil {
+				return 0, nil, fmt.Errorf("[arrayDecoder| close array]: %w: %w", ErrInvalidJSON, err)
+			}
+		}
+		return 0, nil, io.EOF
+	}
+
+	d.index++
+	var order models.Order
+	err := d.dec.Decode(&order)
+
+	// значение неподходящего типа декодер пропускает целиком, и разбор можно продолжить
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return d.index, nil, &LineError{Line: d.index, Err: fmt.Errorf("%w: %w", ErrInvalidJSON, err)}
+	}
+	if err != nil {
+		if !errors.Is(err, ErrBodyTooLarge) {
+			err = fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+		}
+		return 0, nil, fmt.Errorf("[arrayDecoder| element %d]: %w", d.index, err)
+	}
+	return d.index, &order, nil
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// LimitReader читает из r не больше n байт, а на следующем байте возвращает ErrBodyTooLarge. n <= 0 - без ограничения
+func LimitReader(r io.Reader, n int64) io.Reader {
+	if n <= 0 {
+		return r
+	}
+	return &limitReader{r: r, left: n}
+}
+
+type limitReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	// читаем на байт больше лимита, чтобы отличить тело ровно в n байт от более длинного
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n + int(l.left), ErrBodyTooLarge
+	}
+	return n, err
+}
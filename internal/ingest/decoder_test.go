@@ -0,0 +1,97 @@
+package ingest
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decoded struct {
+	Line  int
+	Track string
+	Err   error
+}
+
+func decodeAll(t *testing.T, r io.Reader) ([]decoded, error) {
+	t.Helper()
+	dec, err := NewDecoder(r)
+	require.NoError(t, err)
+
+	var out []decoded
+	for {
+		line, order, err := dec.Next()
+		if err == io.EOF {
+			return out, nil
+		}
+		var lineErr *LineError
+		if errors.As(err, &lineErr) {
+			out = append(out, decoded{Line: line, Err: lineErr.Err})
+			continue
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, decoded{Line: line, Track: order.TrackNumber})
+	}
+}
+
+func TestDecoder_NDJSON(t *testing.T) {
+	body := `{"track_number": "A"}` + "\n\n" +
+		`{"track_number": ` + "\r\n" +
+		`{"track_number": "` + strings.Repeat("x", maxLineBytes) + `"}` + "\n" +
+		`{"track_number": "B"}`
+
+	out, err := decodeAll(t, strings.NewReader(body))
+	require.NoError(t, err)
+	require.Len(t, out, 4)
+
+	assert.Equal(t, decoded{Line: 1, Track: "A"}, out[0])
+	assert.Equal(t, 3, out[1].Line)
+	assert.ErrorIs(t, out[1].Err, ErrInvalidJSON)
+	assert.Equal(t, 4, out[2].Line)
+	assert.ErrorIs(t, out[2].Err, ErrLineTooLong)
+	assert.Equal(t, decoded{Line: 5, Track: "B"}, out[3])
+}
+
+func TestDecoder_Array(t *testing.T) {
+	body := ` [{"track_number": "A"}, {"track_number": 5}, {"track_number": "B"}]`
+
+	out, err := decodeAll(t, strings.NewReader(body))
+	require.NoError(t, err)
+	require.Len(t, out, 3)
+
+	assert.Equal(t, decoded{Line: 1, Track: "A"}, out[0])
+	assert.Equal(t, 2, out[1].Line)
+	assert.ErrorIs(t, out[1].Err, ErrInvalidJSON)
+	assert.Equal(t, decoded{Line: 3, Track: "B"}, out[2])
+}
+
+func TestDecoder_BrokenArray(t *testing.T) {
+	out, err := decodeAll(t, strings.NewReader(`[{"track_number": "A"}, {"track_number"`))
+	assert.ErrorIs(t, err, ErrInvalidJSON)
+	assert.Equal(t, []decoded{{Line: 1, Track: "A"}}, out)
+}
+
+func TestDecoder_Empty(t *testing.T) {
+	out, err := decodeAll(t, strings.NewReader(" \n"))
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestLimitReader(t *testing.T) {
+	data, err := io.ReadAll(LimitReader(strings.NewReader("12345"), 5))
+	require.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	data, err = io.ReadAll(LimitReader(strings.NewReader("123456"), 5))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, "12345", string(data))
+
+	out, err := decodeAll(t, LimitReader(strings.NewReader(`{"track_number": "A"}`+"\n"+`{"track_number": "B"}`), 25))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Len(t, out, 1)
+}
@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/orders_api/internal/metrics"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/tracing"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// runBatchWorker копит до BatchSize сообщений (но не дольше BatchTimeout с первого сообщения пачки)
+// и записывает их одной транзакцией. Offsets коммитятся только после коммита пачки
+func (kc *KafkaConsumer) runBatchWorker(ctx context.Context, queue <-chan kafka.Message, tracker *offsetTracker, commits chan<- kafka.Message) {
+	batch := make([]kafka.Message, 0, kc.Cfg.BatchSize)
+
+	timer := time.NewTimer(kc.Cfg.BatchTimeout)
+	timer.Stop()
+	var timeout <-chan time.Time
+
+	flush := func() {
+		timer.Stop()
+		timeout = nil
+		if len(batch) == 0 {
+			return
+		}
+
+		start := time.Now()
+		done := kc.processBatch(ctx, batch)
+		metrics.ObserveProcessing(metrics.ModeBatch, start)
+		for i, msg := range batch {
+			if !done[i] {
+				continue
+			}
+			if commitMsg, ok := tracker.markDone(msg); ok {
+				commits <- commitMsg
+			}
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				flush()
+				return
+			}
+
+			// при остановке недописанную пачку не обрабатываем: она не закоммичена и будет перечитана
+			if ctx.Err() != nil {
+				batch = batch[:0]
+				continue
+			}
+
+			batch = append(batch, msg)
+			if len(batch) == 1 {
+				timer.Reset(kc.Cfg.BatchTimeout)
+				timeout = timer.C
+			}
+			if len(batch) >= kc.Cfg.BatchSize {
+				flush()
+			}
+
+		case <-timeout:
+			flush()
+		}
+	}
+}
+
+// decodedOrder заказ сообщения пачки, раскодированный при ее разбиении
+type decodedOrder struct {
+	order *models.Order
+	err   error
+}
+
+// processBatch записывает пачку сообщений и возвращает, какие из них можно коммитить.
+// Новые заказы пишутся одной транзакцией. Остальные сообщения (смена статуса, заказ с версией,
+// который может заменить сохраненный) обрабатываются по одному и делят пачку на части:
+// порядок сообщений партиции сохраняется, а каждая часть коммитится в БД отдельно
+func (kc *KafkaConsumer) processBatch(ctx context.Context, batch []kafka.Message) []bool {
+	done := make([]bool, len(batch))
+	if ctx.Err() != nil {
+		return done
+	}
+
+	decoded := make([]decodedOrder, len(batch))
+	start := 0
+	for i := range batch {
+		if messageType(&batch[i]) == MessageTypeOrderCreate {
+			decoded[i].order, decoded[i].err = decodeOrder(&batch[i])
+			if decoded[i].err != nil || !decoded[i].order.IsVersioned() {
+				continue
+			}
+		}
+		copy(done[start:i], kc.processOrdersBatch(ctx, batch[start:i], decoded[start:i]))
+		done[i] = kc.handleMessage(ctx, &batch[i])
+		start = i + 1
+	}
+	copy(done[start:], kc.processOrdersBatch(ctx, batch[start:], decoded[start:]))
+
+	return done
+}
+
+// processOrdersBatch записывает пачку новых заказов одной транзакцией; decoded - заказы сообщений пачки.
+// Сообщения, на которых пачка споткнулась, уходят в обычный путь обработки ошибок: ретраи и DLQ
+func (kc *KafkaConsumer) processOrdersBatch(ctx context.Context, batch []kafka.Message, decoded []decodedOrder) []bool {
+	done := make([]bool, len(batch))
+	if len(batch) == 0 || ctx.Err() != nil {
+		return done
+	}
+
+	orders := make([]*models.Order, 0, len(batch))
+	orderIdx := make([]int, 0, len(batch))
+	for i := range batch {
+		if decoded[i].err != nil {
+			done[i] = kc.deadLetter(ctx, &batch[i], fmt.Errorf("[processOrdersBatch| failed to decode]: %w", decoded[i].err), 1)
+			continue
+		}
+		orders = append(orders, decoded[i].order)
+		orderIdx = append(orderIdx, i)
+	}
+
+	// спан пачки связан с трассами всех ее сообщений
+	links := make([]trace.Link, 0, len(orderIdx))
+	for _, i := range orderIdx {
+		links = append(links, trace.LinkFromContext(tracing.ExtractMessage(ctx, &batch[i])))
+	}
+	batchCtx, span := tracing.Tracer().Start(ctx, "kafka consume batch "+batch[0].Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer), trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("messaging.batch.message_count", len(batch))))
+	orderErrs, err := kc.Service.SetOrders(context.WithoutCancel(batchCtx), orders)
+	tracing.End(span, err)
+	if err != nil {
+		// пачку записать не удалось: обработаем сообщения по одному с ретраями
+		slog.Warn("Failed to SetOrders, processing batch one by one", "error", err, "orders", len(orders))
+		for _, i := range orderIdx {
+			done[i] = kc.handleMessage(ctx, &batch[i])
+		}
+		return done
+	}
+
+	var saved int
+	for j, orderErr := range orderErrs {
+		i := orderIdx[j]
+		switch {
+		case orderErr == nil:
+			done[i] = true
+			saved++
+		case IsTransient(orderErr):
+			done[i] = kc.handleMessage(ctx, &batch[i])
+		default:
+			done[i] = kc.deadLetter(ctx, &batch[i], fmt.Errorf("[processOrdersBatch| failed to SetOrders]: %w", orderErr), 1)
+		}
+	}
+
+	slog.Info("Success readed batch and set orders", "orders", saved, "batch", len(batch))
+	return done
+}
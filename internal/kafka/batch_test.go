@@ -0,0 +1,213 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/orders_api/internal/models"
+	mock_service "github.com/orders_api/internal/service/mocks"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestConsumer(t *testing.T, batchSize int) (*KafkaConsumer, *mock_service.MockServiceOrder) {
+	ctrl := gomock.NewController(t)
+	srvc := mock_service.NewMockServiceOrder(ctrl)
+
+	cfg := &KafkaConfig{
+		RetryMaxAttempts:    3,
+		RetryInitialBackoff: time.Millisecond,
+		RetryMaxBackoff:     time.Millisecond,
+		RetryMultiplier:     2,
+		BatchSize:           batchSize,
+		BatchTimeout:        20 * time.Millisecond,
+	}
+	return NewKafkaConsumer(nil, nil, cfg, srvc), srvc
+}
+
+func orderMessage(t *testing.T, offset int64, order *models.Order) kafka.Message {
+	t.Helper()
+	value, err := json.Marshal(order)
+	require.NoError(t, err)
+	return kafka.Message{Topic: "orders", Offset: offset, Value: value}
+}
+
+func statusMessage(t *testing.T, offset int64, req *models.StatusChangeRequest) kafka.Message {
+	t.Helper()
+	value, err := json.Marshal(req)
+	require.NoError(t, err)
+	return kafka.Message{
+		Topic:   "orders",
+		Offset:  offset,
+		Value:   value,
+		Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte(MessageTypeOrderStatus)}},
+	}
+}
+
+// orderUIDs сопоставляет заказы пачки по order_uid: заказы раскодированы из сообщений заново
+func orderUIDs(orders ...*models.Order) gomock.Matcher {
+	uids := make([]uuid.UUID, len(orders))
+	for i, order := range orders {
+		uids[i] = order.OrderUID
+	}
+	return gomock.Cond(func(x any) bool {
+		got := x.([]*models.Order)
+		if len(got) != len(uids) {
+			return false
+		}
+		for i, order := range got {
+			if order.OrderUID != uids[i] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func orderUID(order *models.Order) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		return x.(*models.Order).OrderUID == order.OrderUID
+	})
+}
+
+func newOrder() *models.Order {
+	return &models.Order{OrderUID: uuid.Must(uuid.NewV4())}
+}
+
+func TestProcessBatch_KeepsOrderAroundSingleMessages(t *testing.T) {
+	kc, srvc := newTestConsumer(t, 10)
+
+	a, b, c := newOrder(), newOrder(), newOrder()
+	updated := &models.Order{OrderUID: a.OrderUID, Version: 2}
+	status := &models.StatusChangeRequest{OrderUID: b.OrderUID.String(), Status: models.StatusPaid, Actor: "payments"}
+
+	batch := []kafka.Message{
+		orderMessage(t, 0, a),
+		orderMessage(t, 1, b),
+		orderMessage(t, 2, updated),
+		statusMessage(t, 3, status),
+		orderMessage(t, 4, c),
+	}
+
+	// новая версия заказа применяется после записи самого заказа, а не раньше пачки
+	gomock.InOrder(
+		srvc.EXPECT().SetOrders(gomock.Any(), orderUIDs(a, b)).Return([]error{nil, nil}, nil),
+		srvc.EXPECT().SetOrder(gomock.Any(), orderUID(updated)).Return(updated, nil),
+		srvc.EXPECT().ChangeOrderStatus(gomock.Any(), status.OrderUID, status).Return(&models.StatusChange{}, nil),
+		srvc.EXPECT().SetOrders(gomock.Any(), orderUIDs(c)).Return([]error{nil}, nil),
+	)
+
+	done := kc.processBatch(context.Background(), batch)
+	assert.Equal(t, []bool{true, true, true, true, true}, done)
+}
+
+func TestProcessOrdersBatch_FallsBackToSingleMessages(t *testing.T) {
+	transient := &pgconn.PgError{Code: "08006"}
+
+	t.Run("Batch_failed", func(t *testing.T) {
+		kc, srvc := newTestConsumer(t, 10)
+		a, b := newOrder(), newOrder()
+
+		gomock.InOrder(
+			srvc.EXPECT().SetOrders(gomock.Any(), orderUIDs(a, b)).Return(nil, transient),
+			srvc.EXPECT().SetOrder(gomock.Any(), orderUID(a)).Return(a, nil),
+			srvc.EXPECT().SetOrder(gomock.Any(), orderUID(b)).Return(b, nil),
+		)
+
+		done := kc.processBatch(context.Background(), []kafka.Message{orderMessage(t, 0, a), orderMessage(t, 1, b)})
+		assert.Equal(t, []bool{true, true}, done)
+	})
+
+	t.Run("Transient_order_error", func(t *testing.T) {
+		kc, srvc := newTestConsumer(t, 10)
+		a, b := newOrder(), newOrder()
+
+		// повторяется только заказ с временной ошибкой, записанный в пачке заказ не пишется второй раз
+		gomock.InOrder(
+			srvc.EXPECT().SetOrders(gomock.Any(), orderUIDs(a, b)).Return([]error{nil, transient}, nil),
+			srvc.EXPECT().SetOrder(gomock.Any(), orderUID(b)).Return(nil, transient),
+			srvc.EXPECT().SetOrder(gomock.Any(), orderUID(b)).Return(b, nil),
+		)
+
+		done := kc.processBatch(context.Background(), []kafka.Message{orderMessage(t, 0, a), orderMessage(t, 1, b)})
+		assert.Equal(t, []bool{true, true}, done)
+	})
+}
+
+func TestRunBatchWorker(t *testing.T) {
+	t.Run("Commits_after_batch", func(t *testing.T) {
+		kc, srvc := newTestConsumer(t, 2)
+
+		orders := []*models.Order{newOrder(), newOrder(), newOrder()}
+		tracker := newOffsetTracker()
+		queue := make(chan kafka.Message, len(orders))
+		for i, order := range orders {
+			msg := orderMessage(t, int64(i), order)
+			tracker.track(msg)
+			queue <- msg
+		}
+		commits := make(chan kafka.Message, len(orders))
+
+		gomock.InOrder(
+			srvc.EXPECT().SetOrders(gomock.Any(), orderUIDs(orders[0], orders[1])).
+				DoAndReturn(func(context.Context, []*models.Order) ([]error, error) {
+					// пока пачка не записана, ее offsets не коммитятся
+					assert.Empty(t, commits)
+					return []error{nil, nil}, nil
+				}),
+			// неполная пачка записывается по BatchTimeout
+			srvc.EXPECT().SetOrders(gomock.Any(), orderUIDs(orders[2])).
+				DoAndReturn(func(context.Context, []*models.Order) ([]error, error) {
+					assert.Len(t, commits, 2)
+					return []error{nil}, nil
+				}),
+		)
+
+		finished := make(chan struct{})
+		go func() {
+			kc.runBatchWorker(context.Background(), queue, tracker, commits)
+			close(finished)
+		}()
+
+		require.Eventually(t, func() bool { return len(commits) == len(orders) }, time.Second, 5*time.Millisecond)
+		close(queue)
+		<-finished
+		for i := range orders {
+			assert.Equal(t, int64(i), (<-commits).Offset)
+		}
+	})
+
+	t.Run("Stop_during_batch_commits_nothing", func(t *testing.T) {
+		kc, srvc := newTestConsumer(t, 2)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		orders := []*models.Order{newOrder(), newOrder()}
+		tracker := newOffsetTracker()
+		queue := make(chan kafka.Message, len(orders))
+		for i, order := range orders {
+			msg := orderMessage(t, int64(i), order)
+			tracker.track(msg)
+			queue <- msg
+		}
+		close(queue)
+		commits := make(chan kafka.Message, len(orders))
+
+		// сервис останавливается, пока пачка пишется: транзакция не прошла, сообщения перечитаются после перезапуска
+		srvc.EXPECT().SetOrders(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, []*models.Order) ([]error, error) {
+				cancel()
+				return nil, &pgconn.PgError{Code: "08006"}
+			})
+		srvc.EXPECT().SetOrder(gomock.Any(), gomock.Any()).Return(nil, &pgconn.PgError{Code: "08006"}).AnyTimes()
+
+		kc.runBatchWorker(ctx, queue, tracker, commits)
+		assert.Empty(t, commits)
+	})
+}
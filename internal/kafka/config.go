@@ -1,8 +1,41 @@
 package kafka
 
+import "time"
+
+// способы распределения сообщений по воркерам
+const (
+	PartitionByPartition = "partition"
+	PartitionByKey       = "key"
+)
+
 type KafkaConfig struct {
 	ExternalPort int    `env:"KAFKA_EXTERNAL_PORT" envDefault:"9092"`
 	Topic        string `env:"KAFKA_TOPIC,required"`
 	Group        string `env:"KAFKA_GROUP,required"`
 	Address      string `env:"KAFKA_ADDRESS" envDefault:"kafka"`
+	DLQTopic     string `env:"KAFKA_DLQ_TOPIC" envDefault:"orders_dlq"`
+	ClientID     string `env:"KAFKA_CLIENT_ID"` // по нему /readyz находит консьюмер в группе; пустое значение - orders_api-<hostname>
+
+	// повторные попытки обработки при временных ошибках
+	RetryMaxAttempts    int           `env:"KAFKA_RETRY_MAX_ATTEMPTS" envDefault:"5"`
+	RetryInitialBackoff time.Duration `env:"KAFKA_RETRY_INITIAL_BACKOFF" envDefault:"200ms"`
+	RetryMaxBackoff     time.Duration `env:"KAFKA_RETRY_MAX_BACKOFF" envDefault:"10s"`
+	RetryMultiplier     float64       `env:"KAFKA_RETRY_MULTIPLIER" envDefault:"2"`
+	RetryJitter         float64       `env:"KAFKA_RETRY_JITTER" envDefault:"0.2"`
+
+	// параллельная обработка: сообщения распределяются по воркерам по партиции или по ключу.
+	// Воркеры берут соединения из общего пула, поэтому их число не должно превышать DB_POOL_MAX_CONNS
+	Workers         int    `env:"KAFKA_WORKERS" envDefault:"1"`
+	PartitionBy     string `env:"KAFKA_PARTITION_BY" envDefault:"partition"`
+	WorkerQueueSize int    `env:"KAFKA_WORKER_QUEUE_SIZE" envDefault:"100"`
+
+	// пакетная запись: воркер копит до BatchSize сообщений, но не дольше BatchTimeout.
+	// BatchSize <= 1 отключает пакетный режим
+	BatchSize    int           `env:"KAFKA_BATCH_SIZE" envDefault:"1"`
+	BatchTimeout time.Duration `env:"KAFKA_BATCH_TIMEOUT" envDefault:"500ms"`
+
+	// исходящий топик событий жизненного цикла заказа и настройки outbox relay
+	EventsTopic        string        `env:"KAFKA_EVENTS_TOPIC" envDefault:"orders_events"`
+	OutboxPollInterval time.Duration `env:"KAFKA_OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	OutboxBatchSize    int           `env:"KAFKA_OUTBOX_BATCH_SIZE" envDefault:"100"`
 }
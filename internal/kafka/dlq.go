@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/utils"
+	"github.com/segmentio/kafka-go"
+)
+
+// заголовки, которыми помечается сообщение, отправленное в dead-letter топик
+const (
+	HeaderDLQReason          = "x-dlq-reason"
+	HeaderDLQError           = "x-dlq-error"
+	HeaderDLQSourceTopic     = "x-dlq-source-topic"
+	HeaderDLQSourcePartition = "x-dlq-source-partition"
+	HeaderDLQSourceOffset    = "x-dlq-source-offset"
+	HeaderDLQAttempts        = "x-dlq-attempts"
+	// JSON список полей заказа, не прошедших проверку: [{"field":"items[1].sale","rule":"lte","param":"100","message":"..."}]
+	HeaderDLQFields = "x-dlq-fields"
+)
+
+// причины, по которым сообщение попало в dead-letter топик
+const (
+	ReasonUnmarshal = "unmarshal"
+	ReasonValidate  = "validate"
+	ReasonDuplicate = "duplicate"
+	ReasonRetries   = "retries_exhausted"
+	ReasonType      = "unknown_type"
+	ReasonStatus    = "invalid_status"
+	ReasonNotFound  = "not_found"
+	ReasonUnknown   = "unknown"
+)
+
+var ErrUnmarshalMessage = errors.New("failed to unmarshal message")
+
+func NewDLQWriter(cfg *KafkaConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(fmt.Sprintf("%s:%d", cfg.Address, cfg.ExternalPort)),
+		Topic:                  cfg.DLQTopic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: false,
+	}
+}
+
+// FailureReason определяет причину ошибки обработки сообщения по цепочке обернутых ошибок
+func FailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrRetriesExhausted):
+		return ReasonRetries
+	case errors.Is(err, ErrUnmarshalMessage):
+		return ReasonUnmarshal
+	case errors.Is(err, ErrUnknownMessageType):
+		return ReasonType
+	case errors.Is(err, service.ErrValidateJSON),
+		errors.Is(err, service.ErrInvalidUUID):
+		return ReasonValidate
+	case errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidStatusTransition):
+		return ReasonStatus
+	case errors.Is(err, repository.ErrOrderNotFoundByUUID):
+		return ReasonNotFound
+	case errors.Is(err, repository.ErrOrderAlreadyExistsUUID),
+		errors.Is(err, repository.ErrOrderAlreadyExistsTrack):
+		return ReasonDuplicate
+	default:
+		return ReasonUnknown
+	}
+}
+
+// NewDLQMessage собирает копию исходного сообщения с заголовками о причине ошибки,
+// attempts - количество попыток обработки, сделанных консьюмером
+func NewDLQMessage(msg *kafka.Message, procErr error, attempts int) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+7)
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case HeaderDLQAttempts:
+			// если сообщение уже переотправлялось из DLQ, продолжим счетчик попыток
+			if prev, err := strconv.Atoi(string(h.Value)); err == nil {
+				attempts += prev
+			}
+			continue
+		case HeaderDLQReason, HeaderDLQError, HeaderDLQSourceTopic, HeaderDLQSourcePartition, HeaderDLQSourceOffset, HeaderDLQFields:
+			continue
+		}
+		headers = append(headers, h)
+	}
+
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQReason, Value: []byte(FailureReason(procErr))},
+		kafka.Header{Key: HeaderDLQError, Value: []byte(procErr.Error())},
+		kafka.Header{Key: HeaderDLQSourceTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQSourcePartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQSourceOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+	)
+	// продюсеру нужно знать, какие поля исправить
+	if fields := utils.FieldErrors(procErr); fields != nil {
+		if value, err := json.Marshal(fields); err == nil {
+			headers = append(headers, kafka.Header{Key: HeaderDLQFields, Value: value})
+		}
+	}
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// sendToDLQ публикует сообщение, которое не удалось обработать, в dead-letter топик
+func (kc *KafkaConsumer) sendToDLQ(ctx context.Context, msg *kafka.Message, procErr error, attempts int) error {
+	dlqMsg := NewDLQMessage(msg, procErr, attempts)
+
+	err := kc.DLQWriter.WriteMessages(ctx, dlqMsg)
+	if err != nil {
+		return fmt.Errorf("[sendToDLQ| write message]: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/utils"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func headersToMap(headers []kafka.Header) map[string]string {
+	res := make(map[string]string, len(headers))
+	for _, h := range headers {
+		res[h.Key] = string(h.Value)
+	}
+	return res
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Err      error
+		Expected string
+	}{
+		{
+			Name:     "Unmarshal",
+			Err:      fmt.Errorf("[ProcessMessage| failed to Unmarshal]: %w: %w", ErrUnmarshalMessage, errors.New("unexpected end of JSON input")),
+			Expected: ReasonUnmarshal,
+		},
+		{
+			Name:     "Validate",
+			Err:      fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w", service.ErrValidateJSON),
+			Expected: ReasonValidate,
+		},
+		{
+			Name:     "Duplicate_uuid",
+			Err:      fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w", repository.ErrOrderAlreadyExistsUUID),
+			Expected: ReasonDuplicate,
+		},
+		{
+			Name:     "Duplicate_track",
+			Err:      fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w", repository.ErrOrderAlreadyExistsTrack),
+			Expected: ReasonDuplicate,
+		},
+		{
+			Name:     "Retries_exhausted",
+			Err:      fmt.Errorf("[processWithRetry| 5 attempts]: %w: %w", ErrRetriesExhausted, context.DeadlineExceeded),
+			Expected: ReasonRetries,
+		},
+		{
+			Name:     "Unknown",
+			Err:      errors.New("something went wrong"),
+			Expected: ReasonUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, FailureReason(tt.Err))
+		})
+	}
+}
+
+func TestNewDLQMessage(t *testing.T) {
+	procErr := fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w", service.ErrValidateJSON)
+
+	t.Run("First_failure", func(t *testing.T) {
+		msg := &kafka.Message{
+			Topic:     "orders",
+			Partition: 2,
+			Offset:    42,
+			Key:       []byte("key"),
+			Value:     []byte(`{"order_uid":"bad"}`),
+			Headers:   []kafka.Header{{Key: "trace", Value: []byte("abc")}},
+		}
+
+		dlqMsg := NewDLQMessage(msg, procErr, 1)
+
+		assert.Equal(t, msg.Key, dlqMsg.Key)
+		assert.Equal(t, msg.Value, dlqMsg.Value)
+		assert.Empty(t, dlqMsg.Topic)
+
+		headers := headersToMap(dlqMsg.Headers)
+		assert.Equal(t, "abc", headers["trace"])
+		assert.Equal(t, ReasonValidate, headers[HeaderDLQReason])
+		assert.Equal(t, procErr.Error(), headers[HeaderDLQError])
+		assert.Equal(t, "orders", headers[HeaderDLQSourceTopic])
+		assert.Equal(t, "2", headers[HeaderDLQSourcePartition])
+		assert.Equal(t, "42", headers[HeaderDLQSourceOffset])
+		assert.Equal(t, "1", headers[HeaderDLQAttempts])
+	})
+
+	t.Run("Redriven_message", func(t *testing.T) {
+		msg := &kafka.Message{
+			Topic:     "orders",
+			Partition: 0,
+			Offset:    7,
+			Value:     []byte(`{}`),
+			Headers: []kafka.Header{
+				{Key: HeaderDLQReason, Value: []byte(ReasonUnknown)},
+				{Key: HeaderDLQSourceOffset, Value: []byte("3")},
+				{Key: HeaderDLQAttempts, Value: []byte("2")},
+			},
+		}
+
+		dlqMsg := NewDLQMessage(msg, procErr, 3)
+
+		// старые заголовки DLQ заменяются новыми, а не дублируются
+		assert.Len(t, dlqMsg.Headers, 6)
+
+		headers := headersToMap(dlqMsg.Headers)
+		assert.Equal(t, ReasonValidate, headers[HeaderDLQReason])
+		assert.Equal(t, "7", headers[HeaderDLQSourceOffset])
+		assert.Equal(t, "5", headers[HeaderDLQAttempts])
+	})
+
+	t.Run("Validation_fields", func(t *testing.T) {
+		validateErr := utils.VaildateStructs(&models.Delivery{Name: "Test Testov", City: "Kiryat Mozkin"})
+		procErr := fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w: %w", service.ErrValidateJSON, validateErr)
+
+		dlqMsg := NewDLQMessage(&kafka.Message{Topic: "orders", Value: []byte(`{}`)}, procErr, 1)
+
+		headers := headersToMap(dlqMsg.Headers)
+		assert.Equal(t, ReasonValidate, headers[HeaderDLQReason])
+		assert.JSONEq(t, `[{"field":"phone","rule":"required","message":"is required"}]`, headers[HeaderDLQFields])
+	})
+}
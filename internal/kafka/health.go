@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var ErrNotGroupMember = errors.New("consumer is not a member of the group")
+
+// clientID идентификатор консьюмера в группе, у каждого экземпляра сервиса свой
+func clientID(cfg *KafkaConfig) string {
+	if cfg.ClientID != "" {
+		return cfg.ClientID
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "orders_api"
+	}
+	return "orders_api-" + hostname
+}
+
+// CheckBroker проверяет, что брокер Kafka принимает соединения
+func (kc *KafkaConsumer) CheckBroker(ctx context.Context) error {
+	conn, err := kc.Reader.Config().Dialer.DialContext(ctx, "tcp", kc.Reader.Config().Brokers[0])
+	if err != nil {
+		return fmt.Errorf("[CheckBroker| dial]: %w", err)
+	}
+	return conn.Close()
+}
+
+// CheckGroupMembership проверяет, что консьюмер вошел в группу и участвует в распределении партиций
+func (kc *KafkaConsumer) CheckGroupMembership(ctx context.Context) error {
+	cfg := kc.Reader.Config()
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...)}
+
+	resp, err := client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{cfg.GroupID}})
+	if err != nil {
+		return fmt.Errorf("[CheckGroupMembership| describe group]: %w", err)
+	}
+	for _, group := range resp.Groups {
+		if group.Error != nil {
+			return fmt.Errorf("[CheckGroupMembership| group %s]: %w", group.GroupID, group.Error)
+		}
+		for _, member := range group.Members {
+			if member.ClientID == cfg.Dialer.ClientID {
+				return nil
+			}
+		}
+		return fmt.Errorf("[CheckGroupMembership| group %s in state %s]: %w", group.GroupID, group.GroupState, ErrNotGroupMember)
+	}
+	return fmt.Errorf("[CheckGroupMembership| group %s]: %w", cfg.GroupID, ErrNotGroupMember)
+}
@@ -5,24 +5,46 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/orders_api/internal/metrics"
 	"github.com/orders_api/internal/models"
 	"github.com/orders_api/internal/service"
+	"github.com/orders_api/internal/tracing"
+	"github.com/orders_api/internal/utils"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// типы входящих сообщений, задаются заголовком event-type
+const (
+	MessageTypeOrderCreate = "order.create"
+	MessageTypeOrderStatus = "order.status"
+)
+
+var ErrUnknownMessageType = errors.New("unknown message type")
+
+// commitTimeout ограничивает время коммита offsets, в том числе при остановке сервиса
+const commitTimeout = 5 * time.Second
+
 type KafkaConsumer struct {
-	Reader  *kafka.Reader
-	Service service.ServiceOrder
-	Cfg     *KafkaConfig
+	Reader    *kafka.Reader
+	DLQWriter *kafka.Writer
+	Service   service.ServiceOrder
+	Cfg       *KafkaConfig
 }
 
-func NewKafkaConsumer(reader *kafka.Reader, cfg *KafkaConfig, srvc service.ServiceOrder) *KafkaConsumer {
+func NewKafkaConsumer(reader *kafka.Reader, dlqWriter *kafka.Writer, cfg *KafkaConfig, srvc service.ServiceOrder) *KafkaConsumer {
 	return &KafkaConsumer{
-		Reader:  reader,
-		Cfg:     cfg,
-		Service: srvc,
+		Reader:    reader,
+		DLQWriter: dlqWriter,
+		Cfg:       cfg,
+		Service:   srvc,
 	}
 }
 
@@ -40,55 +62,218 @@ func NewReader(cfg *KafkaConfig) (*kafka.Reader, error) {
 		Brokers: []string{addr},
 		Topic:   cfg.Topic,
 		GroupID: cfg.Group,
+		Dialer: &kafka.Dialer{
+			ClientID:  clientID(cfg),
+			Timeout:   10 * time.Second,
+			DualStack: true,
+		},
 	}
 
 	return kafka.NewReader(readerConfig), nil
 }
 
+// ReadMessages читает сообщения и раздает их пулу воркеров: сообщения одной партиции
+// (или одного ключа) обрабатываются одним воркером по порядку, разные партиции - параллельно.
+// Возвращает управление после остановки всех воркеров и коммита обработанных сообщений
 func (kc *KafkaConsumer) ReadMessages(ctx context.Context) {
+	workers := max(kc.Cfg.Workers, 1)
+	tracker := newOffsetTracker()
+	commits := make(chan kafka.Message, workers)
+
+	var wg sync.WaitGroup
+	queues := make([]chan kafka.Message, workers)
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, kc.Cfg.WorkerQueueSize)
+
+		wg.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer wg.Done()
+			if kc.Cfg.BatchSize > 1 {
+				kc.runBatchWorker(ctx, queue, tracker, commits)
+				return
+			}
+			kc.runWorker(ctx, queue, tracker, commits)
+		}(queues[i])
+	}
+
+	committerDone := make(chan struct{})
+	go func() {
+		defer close(committerDone)
+		kc.runCommitter(ctx, commits)
+	}()
+
+	kc.fetchMessages(ctx, queues, tracker)
+
+	// дождемся воркеров, затем дозакоммитим то, что они успели обработать
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	close(commits)
+	<-committerDone
+	slog.Info("Consumer workers stopped")
+}
+
+func (kc *KafkaConsumer) fetchMessages(ctx context.Context, queues []chan kafka.Message, tracker *offsetTracker) {
 	for {
+		msg, err := kc.Reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+
+			slog.Error("failed to read message from kafka", "error", err)
+			continue
+		}
+
+		metrics.KafkaConsumed.Inc()
+		tracker.track(msg)
+
 		select {
+		case queues[kc.route(&msg, len(queues))] <- msg:
 		case <-ctx.Done():
 			return
-		default:
-			msg, err := kc.Reader.FetchMessage(ctx)
-			if err != nil {
-				if errors.Is(err, context.Canceled) {
-					return
-				}
-
-				slog.Error("failed to read message from kafka", "error", err)
-				continue
-			}
-			err = kc.ProcessMessage(&msg, ctx)
-			if err != nil {
-				slog.Error("Failed to ProcessMessage", "error", err)
-				// закоммитим невалидное сообщение, чтобы больше его не читать
-				err = kc.Reader.CommitMessages(ctx, msg)
-				if err != nil {
-					slog.Error("Failed to Commit Message", "error", err)
-				}
-				continue
-			}
+		}
+	}
+}
 
-			// закоммитим сообщение (оно удачно загрузилось в БД)
-			err = kc.Reader.CommitMessages(ctx, msg)
-			if err != nil {
-				slog.Error("Failed to Commit Message", "error", err)
-				continue
-			}
+// route выбирает воркера для сообщения так, чтобы порядок внутри партиции (ключа) сохранялся
+func (kc *KafkaConsumer) route(msg *kafka.Message, workers int) int {
+	if kc.Cfg.PartitionBy == PartitionByKey && len(msg.Key) > 0 {
+		h := fnv.New32a()
+		h.Write(msg.Key)
+		return int(h.Sum32() % uint32(workers))
+	}
+	return msg.Partition % workers
+}
+
+func (kc *KafkaConsumer) runWorker(ctx context.Context, queue <-chan kafka.Message, tracker *offsetTracker, commits chan<- kafka.Message) {
+	for msg := range queue {
+		// при остановке оставшиеся в очереди сообщения не обрабатываем: они не закоммичены и будут перечитаны
+		if ctx.Err() != nil {
+			continue
+		}
+
+		start := time.Now()
+		handled := kc.handleMessage(ctx, &msg)
+		metrics.ObserveProcessing(metrics.ModeMessage, start)
+		if !handled {
+			continue
+		}
+
+		if commitMsg, ok := tracker.markDone(msg); ok {
+			commits <- commitMsg
+		}
+	}
+}
+
+// handleMessage обрабатывает сообщение с ретраями, а неудачные отправляет в DLQ.
+// Возвращает false, если сообщение нельзя коммитить
+func (kc *KafkaConsumer) handleMessage(ctx context.Context, msg *kafka.Message) bool {
+	// трасса продолжается из заголовков сообщения, если продюсер их передал
+	ctx, span := tracing.Tracer().Start(tracing.ExtractMessage(ctx, msg), "kafka consume "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer), tracing.MessageAttributes(msg))
+	defer span.End()
+
+	attempts, err := kc.processWithRetry(ctx, msg)
+	span.SetAttributes(attribute.Int("messaging.attempts", attempts))
+	if err == nil {
+		return true
+	}
+
+	// сервис останавливается посреди ретраев: не коммитим, сообщение дочитается после перезапуска
+	if ctx.Err() != nil {
+		return false
+	}
+
+	return kc.deadLetter(ctx, msg, err, attempts)
+}
+
+// deadLetter логирует ошибку обработки и отправляет сообщение в DLQ.
+// Возвращает false, если сообщение нельзя коммитить
+func (kc *KafkaConsumer) deadLetter(ctx context.Context, msg *kafka.Message, err error, attempts int) bool {
+	metrics.KafkaFailed.WithLabelValues(FailureReason(err)).Inc()
+	slog.Error("Failed to ProcessMessage",
+		"error", err,
+		"reason", FailureReason(err),
+		"fields", utils.FieldErrors(err),
+		"attempts", attempts,
+		"partition", msg.Partition,
+		"offset", msg.Offset)
+
+	// отправим сообщение в dead-letter топик, чтобы его можно было разобрать и переотправить.
+	// Пока сообщение не попало в DLQ, следующие сообщения партиции не коммитятся
+	for dlqAttempt := 1; ; dlqAttempt++ {
+		dlqErr := kc.sendToDLQ(ctx, msg, err, attempts)
+		if dlqErr == nil {
+			return true
+		}
+
+		slog.Error("Failed to send message to DLQ", "error", dlqErr,
+			"attempt", dlqAttempt,
+			"partition", msg.Partition,
+			"offset", msg.Offset)
+
+		timer := time.NewTimer(kc.Cfg.Backoff(dlqAttempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
 		}
 	}
 }
 
-func (kc *KafkaConsumer) ProcessMessage(msg *kafka.Message, ctx context.Context) error {
-	var newOrder models.Order
-	err := json.Unmarshal(msg.Value, &newOrder)
+// runCommitter коммитит offsets по мере того, как партиции обрабатываются по порядку
+func (kc *KafkaConsumer) runCommitter(ctx context.Context, commits <-chan kafka.Message) {
+	committed := make(map[int]int64)
+
+	for msg := range commits {
+		// воркеры могут прислать offsets одной партиции не по порядку, откатывать коммит назад нельзя
+		if last, ok := committed[msg.Partition]; ok && msg.Offset <= last {
+			continue
+		}
+
+		// коммит должен дойти и после отмены ctx, чтобы обработанные при остановке сообщения не перечитывались
+		commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), commitTimeout)
+		err := kc.Reader.CommitMessages(commitCtx, msg)
+		cancel()
+		if err != nil {
+			metrics.KafkaCommits.WithLabelValues("error").Inc()
+			slog.Error("Failed to Commit Message", "error", err,
+				"partition", msg.Partition,
+				"offset", msg.Offset)
+			continue
+		}
+		metrics.KafkaCommits.WithLabelValues("ok").Inc()
+		committed[msg.Partition] = msg.Offset
+	}
+}
+
+func (kc *KafkaConsumer) ProcessMessage(msg *kafka.Message, ctx context.Context) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ProcessMessage", trace.WithAttributes(attribute.String("messaging.message.type", messageType(msg))))
+	defer func() { tracing.End(span, err) }()
+
+	// начатая запись в БД доводится до конца и при остановке сервиса
+	ctx = context.WithoutCancel(ctx)
+
+	switch messageType(msg) {
+	case MessageTypeOrderCreate:
+		return kc.processOrder(ctx, msg)
+	case MessageTypeOrderStatus:
+		return kc.processStatusChange(ctx, msg)
+	default:
+		return fmt.Errorf("[ProcessMessage| type %q]: %w", messageType(msg), ErrUnknownMessageType)
+	}
+}
+
+func (kc *KafkaConsumer) processOrder(ctx context.Context, msg *kafka.Message) error {
+	newOrder, err := decodeOrder(msg)
 	if err != nil {
-		return fmt.Errorf("[ProcessMessage| failed to Unmarshal]: %w", err)
+		return fmt.Errorf("[ProcessMessage| failed to decode]: %w", err)
 	}
 
-	_, err = kc.Service.SetOrder(&newOrder)
+	_, err = kc.Service.SetOrder(ctx, newOrder)
 	if err != nil {
 		return fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w", err)
 	}
@@ -96,3 +281,39 @@ func (kc *KafkaConsumer) ProcessMessage(msg *kafka.Message, ctx context.Context)
 	slog.Info("Success readed msg and set order", "order_uid", newOrder.OrderUID)
 	return nil
 }
+
+func (kc *KafkaConsumer) processStatusChange(ctx context.Context, msg *kafka.Message) error {
+	var req models.StatusChangeRequest
+	err := json.Unmarshal(msg.Value, &req)
+	if err != nil {
+		return fmt.Errorf("[ProcessMessage| failed to Unmarshal status change]: %w: %w", ErrUnmarshalMessage, err)
+	}
+
+	_, err = kc.Service.ChangeOrderStatus(ctx, req.OrderUID, &req)
+	if err != nil {
+		return fmt.Errorf("[ProcessMessage| failed to ChangeOrderStatus]: %w", err)
+	}
+
+	slog.Info("Success readed msg and changed order status", "order_uid", req.OrderUID, "status", req.Status)
+	return nil
+}
+
+// messageType возвращает тип сообщения из заголовка event-type.
+// Сообщения без заголовка считаются новыми заказами
+func messageType(msg *kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == HeaderEventType && len(h.Value) > 0 {
+			return string(h.Value)
+		}
+	}
+	return MessageTypeOrderCreate
+}
+
+func decodeOrder(msg *kafka.Message) (*models.Order, error) {
+	var order models.Order
+	err := json.Unmarshal(msg.Value, &order)
+	if err != nil {
+		return nil, fmt.Errorf("[decodeOrder| failed to Unmarshal]: %w: %w", ErrUnmarshalMessage, err)
+	}
+	return &order, nil
+}
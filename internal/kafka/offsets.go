@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker следит за сообщениями, которые обрабатываются параллельно, и отдает
+// на коммит только непрерывный обработанный префикс каждой партиции
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	pending []kafka.Message // сообщения в порядке чтения
+	done    map[int64]bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[int]*partitionOffsets),
+	}
+}
+
+// track регистрирует прочитанное сообщение, вызывается в порядке чтения из партиции
+func (t *offsetTracker) track(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	// партиция перечитывается с закоммиченного offset (например, после ребалансировки):
+	// незакоммиченный хвост будет прочитан заново, старое состояние сбрасываем
+	if !ok || (len(p.pending) > 0 && msg.Offset <= p.pending[len(p.pending)-1].Offset) {
+		p = &partitionOffsets{done: make(map[int64]bool)}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg)
+}
+
+// markDone отмечает сообщение обработанным и возвращает последнее сообщение
+// непрерывного обработанного префикса партиции, если его можно закоммитить
+func (t *offsetTracker) markDone(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	p.done[msg.Offset] = true
+
+	var commit kafka.Message
+	var ready bool
+	for len(p.pending) > 0 && p.done[p.pending[0].Offset] {
+		commit = p.pending[0]
+		ready = true
+		delete(p.done, commit.Offset)
+		p.pending = p.pending[1:]
+	}
+	return commit, ready
+}
@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := newOffsetTracker()
+
+	msgs := []kafka.Message{
+		{Partition: 0, Offset: 10},
+		{Partition: 0, Offset: 11},
+		{Partition: 1, Offset: 5},
+		{Partition: 0, Offset: 12},
+	}
+	for _, msg := range msgs {
+		tracker.track(msg)
+	}
+
+	// 11 обработано раньше 10 - коммитить нельзя
+	_, ok := tracker.markDone(msgs[1])
+	assert.False(t, ok)
+
+	// партиции независимы
+	commit, ok := tracker.markDone(msgs[2])
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), commit.Offset)
+
+	// 10 закрывает префикс 10..11
+	commit, ok = tracker.markDone(msgs[0])
+	assert.True(t, ok)
+	assert.Equal(t, int64(11), commit.Offset)
+
+	commit, ok = tracker.markDone(msgs[3])
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), commit.Offset)
+}
+
+func TestOffsetTracker_Reread(t *testing.T) {
+	tracker := newOffsetTracker()
+
+	tracker.track(kafka.Message{Partition: 0, Offset: 1})
+	tracker.track(kafka.Message{Partition: 0, Offset: 2})
+
+	// после ребалансировки партиция читается заново с offset 1
+	tracker.track(kafka.Message{Partition: 0, Offset: 1})
+
+	commit, ok := tracker.markDone(kafka.Message{Partition: 0, Offset: 1})
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), commit.Offset)
+}
+
+func TestRoute(t *testing.T) {
+	kc := &KafkaConsumer{Cfg: &KafkaConfig{PartitionBy: PartitionByPartition}}
+	assert.Equal(t, 1, kc.route(&kafka.Message{Partition: 5}, 4))
+
+	kc.Cfg.PartitionBy = PartitionByKey
+	first := kc.route(&kafka.Message{Partition: 0, Key: []byte("order-1")}, 4)
+	second := kc.route(&kafka.Message{Partition: 3, Key: []byte("order-1")}, 4)
+	assert.Equal(t, first, second)
+
+	// без ключа - распределение по партиции
+	assert.Equal(t, 2, kc.route(&kafka.Message{Partition: 6}, 4))
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/segmentio/kafka-go"
+)
+
+// заголовки публикуемых событий заказа
+const (
+	HeaderEventType = "event-type"
+	HeaderEventID   = "event-id"
+)
+
+// OutboxRelay переносит события из таблицы outbox в исходящий топик
+type OutboxRelay struct {
+	Writer *kafka.Writer
+	Repo   repository.OutboxRepository
+	Cfg    *KafkaConfig
+}
+
+func NewOutboxRelay(writer *kafka.Writer, repo repository.OutboxRepository, cfg *KafkaConfig) *OutboxRelay {
+	return &OutboxRelay{
+		Writer: writer,
+		Repo:   repo,
+		Cfg:    cfg,
+	}
+}
+
+func NewEventsWriter(cfg *KafkaConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(fmt.Sprintf("%s:%d", cfg.Address, cfg.ExternalPort)),
+		Topic:                  cfg.EventsTopic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: false,
+	}
+}
+
+// Run публикует накопившиеся события, пока не отменен ctx
+func (rl *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(rl.Cfg.OutboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := rl.Repo.ProcessOutbox(ctx, rl.Cfg.OutboxBatchSize, func(events []models.OutboxEvent) error {
+			return rl.publish(ctx, events)
+		})
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Failed to relay outbox events", "error", err)
+		}
+		if n > 0 {
+			slog.Debug("Relayed outbox events", "events", n)
+		}
+
+		// outbox разобран не до конца - забираем следующую пачку сразу
+		if err == nil && n == rl.Cfg.OutboxBatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (rl *OutboxRelay) publish(ctx context.Context, events []models.OutboxEvent) error {
+	msgs := make([]kafka.Message, len(events))
+	for i, e := range events {
+		// ключ - order_uid, чтобы события одного заказа попадали в одну партицию по порядку
+		msgs[i] = kafka.Message{
+			Key:   []byte(e.AggregateID.String()),
+			Value: e.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEventType, Value: []byte(e.Type)},
+				{Key: HeaderEventID, Value: []byte(e.EventID.String())},
+			},
+		}
+	}
+
+	err := rl.Writer.WriteMessages(ctx, msgs...)
+	if err != nil {
+		return fmt.Errorf("[publish| write messages]: %w", err)
+	}
+	return nil
+}
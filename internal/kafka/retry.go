@@ -0,0 +1,140 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	"github.com/segmentio/kafka-go"
+)
+
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// IsPermanent сообщает, что повторная обработка сообщения заведомо закончится той же ошибкой
+func IsPermanent(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, ErrUnmarshalMessage),
+		errors.As(err, &syntaxErr),
+		errors.As(err, &typeErr),
+		errors.Is(err, ErrUnknownMessageType),
+		errors.Is(err, service.ErrValidateJSON),
+		errors.Is(err, service.ErrInvalidUUID),
+		errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidStatusTransition),
+		errors.Is(err, repository.ErrOrderNotFoundByUUID),
+		errors.Is(err, repository.ErrOrderAlreadyExistsUUID),
+		errors.Is(err, repository.ErrOrderAlreadyExistsTrack):
+		return true
+	}
+	return false
+}
+
+// IsTransient сообщает, что ошибка вызвана временной недоступностью БД и обработку стоит повторить
+func IsTransient(err error) bool {
+	if err == nil || IsPermanent(err) {
+		return false
+	}
+
+	// статус заказа изменился между проверкой перехода и записью - проверим переход заново
+	// или версия заказа вставлена параллельно - сравним версии заново
+	if errors.Is(err, repository.ErrOrderStatusConflict) || errors.Is(err, repository.ErrOrderVersionConflict) {
+		return true
+	}
+
+	// ошибки подключения и таймауты pgx
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	// ошибки сервера, после которых запрос можно безопасно повторить
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case len(pgErr.Code) == 5 && pgErr.Code[:2] == "08": // connection_exception
+			return true
+		case pgErr.Code == "40001", // serialization_failure
+			pgErr.Code == "40P01", // deadlock_detected
+			pgErr.Code == "53300", // too_many_connections
+			pgErr.Code == "57P01", // admin_shutdown
+			pgErr.Code == "57P02", // crash_shutdown
+			pgErr.Code == "57P03": // cannot_connect_now
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Backoff возвращает задержку перед повторной попыткой номер attempt (начиная с 1)
+func (cfg *KafkaConfig) Backoff(attempt int) time.Duration {
+	delay := float64(cfg.RetryInitialBackoff) * math.Pow(cfg.RetryMultiplier, float64(attempt-1))
+	if maxDelay := float64(cfg.RetryMaxBackoff); maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	// разбросаем задержку в пределах ±jitter, чтобы реплики не ретраили синхронно
+	if cfg.RetryJitter > 0 {
+		delay += delay * cfg.RetryJitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// processWithRetry обрабатывает сообщение, повторяя попытки при временных ошибках.
+// Возвращает количество сделанных попыток и последнюю ошибку
+func (kc *KafkaConsumer) processWithRetry(ctx context.Context, msg *kafka.Message) (int, error) {
+	maxAttempts := max(kc.Cfg.RetryMaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		err := kc.ProcessMessage(msg, ctx)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if !IsTransient(err) {
+			return attempt, err
+		}
+
+		if attempt >= maxAttempts {
+			return attempt, fmt.Errorf("[processWithRetry| %d attempts]: %w: %w", attempt, ErrRetriesExhausted, err)
+		}
+
+		delay := kc.Cfg.Backoff(attempt)
+		slog.Warn("Transient error while processing message, retrying",
+			"error", err,
+			"attempt", attempt,
+			"delay", delay,
+			"partition", msg.Partition,
+			"offset", msg.Offset)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, fmt.Errorf("[processWithRetry| wait backoff]: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
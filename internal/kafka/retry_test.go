@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	var syntaxErr *json.SyntaxError
+	jsonErr := json.Unmarshal([]byte("{"), &struct{}{})
+	assert.ErrorAs(t, jsonErr, &syntaxErr)
+
+	tests := []struct {
+		Name      string
+		Err       error
+		Transient bool
+	}{
+		{
+			Name:      "Json_syntax",
+			Err:       jsonErr,
+			Transient: false,
+		},
+		{
+			Name:      "Validate",
+			Err:       fmt.Errorf("[ProcessMessage| failed to SetOrder]: %w", service.ErrValidateJSON),
+			Transient: false,
+		},
+		{
+			Name:      "Duplicate",
+			Err:       fmt.Errorf("[InsertOrder| insert order in transaction]: , %w", repository.ErrOrderAlreadyExistsUUID),
+			Transient: false,
+		},
+		{
+			Name:      "Connection_exception",
+			Err:       fmt.Errorf("[InsertOrder| begin transaction]: , %w", &pgconn.PgError{Code: "08006"}),
+			Transient: true,
+		},
+		{
+			Name:      "Serialization_failure",
+			Err:       &pgconn.PgError{Code: "40001"},
+			Transient: true,
+		},
+		{
+			Name:      "Not_null_violation",
+			Err:       &pgconn.PgError{Code: "23502"},
+			Transient: false,
+		},
+		{
+			Name:      "Timeout",
+			Err:       fmt.Errorf("[InsertOrder| commit transaction]: , %w", context.DeadlineExceeded),
+			Transient: true,
+		},
+		{
+			Name:      "Unknown",
+			Err:       errors.New("something went wrong"),
+			Transient: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Transient, IsTransient(tt.Err))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := &KafkaConfig{
+		RetryInitialBackoff: 100 * time.Millisecond,
+		RetryMaxBackoff:     time.Second,
+		RetryMultiplier:     2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, cfg.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, cfg.Backoff(2))
+	assert.Equal(t, 800*time.Millisecond, cfg.Backoff(4))
+	assert.Equal(t, time.Second, cfg.Backoff(10))
+
+	cfg.RetryJitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := cfg.Backoff(2)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+	}
+}
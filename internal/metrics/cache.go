@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"github.com/orders_api/internal/database/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheCollector снимает счетчики кэша заказов при каждом сборе метрик
+type cacheCollector struct {
+	stats func() cache.Stats
+	tier  string
+
+	hits, misses, evictions, expirations, entries, bytes *prometheus.Desc
+}
+
+// NewCacheCollector метрики кэша заказов backend: попадания, промахи, вытеснения и размер.
+// Локальный LRU отдается с tier="local", общий кэш в Redis - с tier="remote"
+func NewCacheCollector(backend string, stats func() cache.Stats) prometheus.Collector {
+	tier := "local"
+	if backend == cache.BackendRedis {
+		tier = "remote"
+	}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", name), help, []string{"tier"}, nil)
+	}
+	return &cacheCollector{
+		stats:       stats,
+		tier:        tier,
+		hits:        desc("hits_total", "Order cache hits."),
+		misses:      desc("misses_total", "Order cache misses."),
+		evictions:   desc("evictions_total", "Orders evicted by the size limits."),
+		expirations: desc("expirations_total", "Orders removed after TTL."),
+		entries:     desc("entries", "Orders in the cache."),
+		bytes:       desc("bytes", "Approximate size of cached orders."),
+	}
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.hits, c.misses, c.evictions, c.expirations, c.entries, c.bytes} {
+		ch <- d
+	}
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	c.collect(ch, &s, c.tier)
+	if s.Remote != nil {
+		c.collect(ch, s.Remote, "remote")
+	}
+}
+
+func (c *cacheCollector) collect(ch chan<- prometheus.Metric, s *cache.Stats, tier string) {
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), tier)
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), tier)
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions), tier)
+	ch <- prometheus.MustNewConstMetric(c.expirations, prometheus.CounterValue, float64(s.Expirations), tier)
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries), tier)
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(s.Bytes), tier)
+}
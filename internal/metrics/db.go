@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/database/postgres"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	dbQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Repository query latency by server and SQL command.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"server", "command"})
+
+	dbQueryErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Failed repository queries by server and SQL command.",
+	}, []string{"server", "command"})
+
+	dbTxFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transaction_failures_total",
+		Help:      "Transactions rolled back or failed to commit.",
+	}, []string{"server"})
+)
+
+// sqlCommands команды, которые получают свою метку; остальные считаются как other
+var sqlCommands = map[string]bool{
+	"select": true, "insert": true, "update": true, "delete": true, "copy": true,
+	"begin": true, "commit": true, "rollback": true, "savepoint": true, "release": true,
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	command string
+	at      time.Time
+}
+
+// QueryTracer pgx tracer, который пишет длительность и ошибки запросов к серверу server
+type QueryTracer struct {
+	server string
+}
+
+// NewQueryTracer tracer для пула соединений с server (primary или адрес реплики)
+func NewQueryTracer(server string) pgx.QueryTracer {
+	return &QueryTracer{server: server}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{command: sqlCommand(data.SQL), at: time.Now()})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	dbQueryDuration.WithLabelValues(t.server, start.command).Observe(time.Since(start.at).Seconds())
+
+	if data.Err != nil {
+		dbQueryErrors.WithLabelValues(t.server, start.command).Inc()
+	}
+	// репозиторий откатывает транзакцию только при ошибке в ней, а COMMIT прерванной транзакции отвечает ROLLBACK
+	if start.command == "rollback" ||
+		(start.command == "commit" && (data.Err != nil || data.CommandTag.String() == "ROLLBACK")) {
+		dbTxFailures.WithLabelValues(t.server).Inc()
+	}
+}
+
+// sqlCommand первое слово запроса в нижнем регистре. Откат к savepoint транзакцию не прерывает
+// и считается как savepoint
+func sqlCommand(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if strings.HasPrefix(strings.ToLower(sql), "rollback to") {
+		return "savepoint"
+	}
+	if i := strings.IndexFunc(sql, func(r rune) bool { return r == ' ' || r == '\n' || r == '\t' || r == '(' }); i >= 0 {
+		sql = sql[:i]
+	}
+	command := strings.ToLower(sql)
+	if !sqlCommands[command] {
+		return "other"
+	}
+	return command
+}
+
+// dbCollector снимает состояние пулов соединений при каждом сборе метрик
+type dbCollector struct {
+	stats func() postgres.DBStats
+
+	conns, maxConns, acquires, emptyAcquires, acquireWait, replicaUp, replicaLag *prometheus.Desc
+}
+
+// NewDBCollector метрики пулов соединений с primary и репликами, доступность и отставание реплик
+func NewDBCollector(stats func() postgres.DBStats) prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", name), help, append([]string{"server"}, labels...), nil)
+	}
+	return &dbCollector{
+		stats:         stats,
+		conns:         desc("pool_conns", "Pool connections by state.", "state"),
+		maxConns:      desc("pool_max_conns", "Maximum pool size."),
+		acquires:      desc("pool_acquires_total", "Connections acquired from the pool."),
+		emptyAcquires: desc("pool_empty_acquires_total", "Acquires that waited for a free connection."),
+		acquireWait:   desc("pool_acquire_wait_seconds_total", "Total time spent waiting for connections."),
+		replicaUp:     desc("replica_up", "Whether the replica serves reads."),
+		replicaLag:    desc("replica_lag_seconds", "Replication lag measured on the last check."),
+	}
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.conns, c.maxConns, c.acquires, c.emptyAcquires, c.acquireWait, c.replicaUp, c.replicaLag} {
+		ch <- d
+	}
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	c.collectPool(ch, &s.Primary, "primary")
+	for _, r := range s.Replicas {
+		c.collectPool(ch, &r.Pool, r.Addr)
+
+		up := 0.0
+		if r.Healthy {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.replicaUp, prometheus.GaugeValue, up, r.Addr)
+		ch <- prometheus.MustNewConstMetric(c.replicaLag, prometheus.GaugeValue, float64(r.LagMs)/1000, r.Addr)
+	}
+}
+
+func (c *dbCollector) collectPool(ch chan<- prometheus.Metric, p *postgres.PoolStats, server string) {
+	ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(p.IdleConns), server, "idle")
+	ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(p.AcquiredConns), server, "acquired")
+	ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(p.ConstructingConns), server, "constructing")
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(p.MaxConns), server)
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(p.AcquireCount), server)
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(p.EmptyAcquireCount), server)
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, float64(p.AcquireDurationMs)/1000, server)
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Выгрузка пишется в ответ уже после того, как HTTPMiddleware записал запрос,
+// поэтому ее длительность и ошибки считаются отдельно, по окончании записи тела
+var (
+	exportStreams = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "export",
+		Name:      "streams_total",
+		Help:      "Order exports streamed to clients, by format and result.",
+	}, []string{"format", "result"})
+
+	exportOrders = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "export",
+		Name:      "orders_total",
+		Help:      "Orders written to exports, by format.",
+	}, []string{"format"})
+
+	exportDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "export",
+		Name:      "stream_duration_seconds",
+		Help:      "Time to stream an export body, by format and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 4, 8),
+	}, []string{"format", "result"})
+)
+
+// ObserveExport записывает выгрузку orders заказов в формате format, начатую в start; err - ошибка, прервавшая ее
+func ObserveExport(format string, orders int, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	exportStreams.WithLabelValues(format, result).Inc()
+	exportOrders.WithLabelValues(format).Add(float64(orders))
+	exportDuration.WithLabelValues(format, result).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+// режимы обработки сообщений консьюмером
+const (
+	ModeMessage = "message"
+	ModeBatch   = "batch"
+)
+
+var (
+	KafkaConsumed = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_consumed_total",
+		Help:      "Messages fetched from the orders topic.",
+	})
+
+	KafkaFailed = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_failed_total",
+		Help:      "Messages that failed processing, by DLQ reason.",
+	}, []string{"reason"})
+
+	KafkaCommits = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "commits_total",
+		Help:      "Offset commits by result.",
+	}, []string{"result"})
+
+	KafkaProcessing = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "processing_duration_seconds",
+		Help:      "Time to process a message or a batch, including retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"mode"})
+)
+
+// ObserveProcessing записывает длительность обработки сообщения или пачки, начатой в start
+func ObserveProcessing(mode string, start time.Time) {
+	KafkaProcessing.WithLabelValues(mode).Observe(time.Since(start).Seconds())
+}
+
+// kafkaReaderCollector снимает статистику kafka.Reader при каждом сборе метрик.
+// Reader.Stats сбрасывает счетчики после вызова, поэтому они накапливаются здесь
+type kafkaReaderCollector struct {
+	stats func() kafka.ReaderStats
+
+	mu         sync.Mutex
+	messages   float64
+	bytes      float64
+	errors     float64
+	rebalances float64
+
+	lag, queue, messagesDesc, bytesDesc, errorsDesc, rebalancesDesc *prometheus.Desc
+}
+
+// NewKafkaReaderCollector метрики консьюмера из Reader.Stats: отставание, очередь и счетчики чтения
+func NewKafkaReaderCollector(stats func() kafka.ReaderStats) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "kafka_reader", name), help, []string{"topic", "partition"}, nil)
+	}
+	return &kafkaReaderCollector{
+		stats:          stats,
+		lag:            desc("lag", "Consumer lag reported by the reader."),
+		queue:          desc("queue_length", "Messages fetched but not yet read."),
+		messagesDesc:   desc("messages_total", "Messages read by the reader."),
+		bytesDesc:      desc("bytes_total", "Bytes read by the reader."),
+		errorsDesc:     desc("errors_total", "Reader errors."),
+		rebalancesDesc: desc("rebalances_total", "Consumer group rebalances."),
+	}
+}
+
+func (c *kafkaReaderCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.lag, c.queue, c.messagesDesc, c.bytesDesc, c.errorsDesc, c.rebalancesDesc} {
+		ch <- d
+	}
+}
+
+func (c *kafkaReaderCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats()
+	c.messages += float64(s.Messages)
+	c.bytes += float64(s.Bytes)
+	c.errors += float64(s.Errors)
+	c.rebalances += float64(s.Rebalances)
+
+	labels := []string{s.Topic, s.Partition}
+	ch <- prometheus.MustNewConstMetric(c.lag, prometheus.GaugeValue, float64(s.Lag), labels...)
+	ch <- prometheus.MustNewConstMetric(c.queue, prometheus.GaugeValue, float64(s.QueueLength), labels...)
+	ch <- prometheus.MustNewConstMetric(c.messagesDesc, prometheus.CounterValue, c.messages, labels...)
+	ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, c.bytes, labels...)
+	ch <- prometheus.MustNewConstMetric(c.errorsDesc, prometheus.CounterValue, c.errors, labels...)
+	ch <- prometheus.MustNewConstMetric(c.rebalancesDesc, prometheus.CounterValue, c.rebalances, labels...)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/orders_api/internal/database/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(HTTPMiddleware())
+	app.Get("/orders/:order_uid", func(c *fiber.Ctx) error {
+		if c.Params("order_uid") == "missing" {
+			return fiber.ErrNotFound
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for _, uid := range []string{"a", "b", "missing"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/orders/"+uid, nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	ok := prometheus.Labels{"method": "GET", "route": "/orders/:order_uid", "status": "200"}
+	notFound := prometheus.Labels{"method": "GET", "route": "/orders/:order_uid", "status": "404"}
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpRequests.With(ok)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.With(notFound)))
+}
+
+func TestSQLCommand(t *testing.T) {
+	tests := map[string]string{
+		"SELECT order_uid FROM \"order\"":  "select",
+		"\n\tINSERT INTO outbox(event_id)": "insert",
+		"begin":                            "begin",
+		"rollback":                         "rollback",
+		"rollback to savepoint sp_1":       "savepoint",
+		"WITH changed AS (SELECT 1)":       "other",
+	}
+	for sql, want := range tests {
+		assert.Equal(t, want, sqlCommand(sql), sql)
+	}
+}
+
+func TestCacheCollector(t *testing.T) {
+	remote := cache.Stats{Hits: 5}
+	collector := NewCacheCollector(cache.BackendTiered, func() cache.Stats {
+		return cache.Stats{Hits: 3, Misses: 1, Entries: 2, Remote: &remote}
+	})
+
+	expected := `
+# HELP orders_api_cache_hits_total Order cache hits.
+# TYPE orders_api_cache_hits_total counter
+orders_api_cache_hits_total{tier="local"} 3
+orders_api_cache_hits_total{tier="remote"} 5
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "orders_api_cache_hits_total")
+	assert.NoError(t, err)
+}
+
+func TestObserveExport(t *testing.T) {
+	ObserveExport("csv", 3, time.Now(), nil)
+	ObserveExport("csv", 1, time.Now(), errors.New("client disconnected"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(exportStreams.WithLabelValues("csv", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(exportStreams.WithLabelValues("csv", "error")))
+	assert.Equal(t, 4.0, testutil.ToFloat64(exportOrders.WithLabelValues("csv")))
+}
@@ -0,0 +1,50 @@
+package models
+
+// ImportStatus результат импорта одного заказа
+type ImportStatus string
+
+const (
+	ImportAccepted  ImportStatus = "accepted"  // заказ сохранен
+	ImportDuplicate ImportStatus = "duplicate" // заказ с таким uid, трек номером или транзакцией уже есть
+	ImportInvalid   ImportStatus = "invalid"   // заказ не разобран или не прошел проверку
+	ImportFailed    ImportStatus = "failed"    // заказ не удалось сохранить, импорт можно повторить
+)
+
+// ImportLine результат импорта заказа из одной строки NDJSON (или одного элемента JSON массива)
+// @Description Номер строки, uid заказа, результат и ошибки, если заказ не сохранен. Fields - поля заказа, не прошедшие проверку
+type ImportLine struct {
+	Line     int          `json:"line" example:"1"`
+	OrderUID string       `json:"order_uid,omitempty" example:"f47ac10b-58cc-4372-a567-0e02b2c3d479"`
+	Status   ImportStatus `json:"status" example:"accepted"`
+	Errors   []string     `json:"errors,omitempty"`
+	Fields   []FieldError `json:"fields,omitempty"`
+}
+
+// ImportReport отчет массового импорта заказов
+// @Description Итоги импорта и результат по каждой строке. Error - причина, по которой импорт остановлен до конца тела.
+// @Description В lines не больше BULK_REPORT_MAX_LINES первых строк, остальные (lines_omitted) учтены только в итогах
+type ImportReport struct {
+	Total        int          `json:"total"`
+	Accepted     int          `json:"accepted"`
+	Duplicates   int          `json:"duplicates"`
+	Invalid      int          `json:"invalid"`
+	Failed       int          `json:"failed"`
+	Error        string       `json:"error,omitempty"`
+	LinesOmitted int          `json:"lines_omitted,omitempty"`
+	Lines        []ImportLine `json:"lines"`
+}
+
+// Count учитывает результат строки в итогах
+func (r *ImportReport) Count(status ImportStatus) {
+	r.Total++
+	switch status {
+	case ImportAccepted:
+		r.Accepted++
+	case ImportDuplicate:
+		r.Duplicates++
+	case ImportInvalid:
+		r.Invalid++
+	case ImportFailed:
+		r.Failed++
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// типы событий жизненного цикла заказа
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderUpdated       = "order.updated"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderCancelled     = "order.cancelled"
+)
+
+// OrderEvent событие жизненного цикла заказа, публикуемое в исходящий топик
+type OrderEvent struct {
+	EventID     uuid.UUID   `json:"event_id"`
+	Type        string      `json:"type"`
+	OrderUID    uuid.UUID   `json:"order_uid"`
+	TrackNumber string      `json:"track_number"`
+	Status      OrderStatus `json:"status"`
+	Version     int64       `json:"version"`
+	Totals      OrderTotals `json:"totals"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+}
+
+// OrderTotals итоговые суммы заказа
+type OrderTotals struct {
+	Currency     string `json:"currency"`
+	Amount       int    `json:"amount"`
+	GoodsTotal   int    `json:"goods_total"`
+	DeliveryCost int    `json:"delivery_cost"`
+	CustomFee    int    `json:"custom_fee"`
+	ItemsCount   int    `json:"items_count"`
+}
+
+// OutboxEvent запись transactional outbox, ожидающая публикации
+type OutboxEvent struct {
+	ID          int64
+	EventID     uuid.UUID
+	Type        string
+	AggregateID uuid.UUID
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+func NewOrderEvent(eventType string, order *Order) (*OrderEvent, error) {
+	eventID, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderEvent{
+		EventID:     eventID,
+		Type:        eventType,
+		OrderUID:    order.OrderUID,
+		TrackNumber: order.TrackNumber,
+		Status:      order.Status,
+		Version:     order.Version,
+		Totals: OrderTotals{
+			Currency:     order.Payment.Currency,
+			Amount:       order.Payment.Amount,
+			GoodsTotal:   order.Payment.GoodsTotal,
+			DeliveryCost: order.Payment.DeliveryCost,
+			CustomFee:    order.Payment.CustomFee,
+			ItemsCount:   len(order.Items),
+		},
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
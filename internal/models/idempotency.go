@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// IdempotencyKey ключ идемпотентности запроса создания заказа
+type IdempotencyKey struct {
+	Key         string    // значение заголовка Idempotency-Key
+	RequestHash string    // отпечаток заказа из запроса, с которым ключ был использован впервые
+	OrderUID    uuid.UUID // созданный по запросу заказ
+	CreatedAt   time.Time
+}
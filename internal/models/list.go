@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// поля сортировки списка заказов
+const (
+	SortByDateCreated = "date_created"
+	SortByAmount      = "amount"
+)
+
+// направления сортировки списка заказов
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// OrderListQuery Параметры запроса списка заказов
+// @Description Фильтры, сортировка и курсор страницы списка заказов. Даты в формате RFC 3339, суммы в минимальных единицах валюты
+type OrderListQuery struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort" validate:"omitempty,oneof=date_created amount"`
+	Order  string `query:"order" validate:"omitempty,oneof=asc desc"`
+
+	CustomerID      string `query:"customer_id"`
+	DeliveryService string `query:"delivery_service"`
+	Entry           string `query:"entry"`
+	Locale          string `query:"locale"`
+	Currency        string `query:"currency"`
+	Provider        string `query:"provider"`
+	Bank            string `query:"bank"`
+
+	DateFrom  string `query:"date_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	DateTo    string `query:"date_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	AmountMin *int   `query:"amount_min" validate:"omitempty,gte=0"`
+	AmountMax *int   `query:"amount_max" validate:"omitempty,gte=0"`
+}
+
+// CustomerOrdersQuery Параметры страницы заказов покупателя
+// @Description Размер страницы и курсор следующей страницы; заказы покупателя отдаются новыми первыми
+type CustomerOrdersQuery struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor"`
+}
+
+// OrderFilter разобранные параметры списка заказов, которые передаются в репозиторий.
+// Пустые строки и nil не ограничивают выборку
+type OrderFilter struct {
+	CustomerID      string
+	DeliveryService string
+	Entry           string
+	Locale          string
+	Currency        string
+	Provider        string
+	Bank            string
+
+	DateFrom  *time.Time // включительно
+	DateTo    *time.Time // не включительно
+	AmountMin *int
+	AmountMax *int
+
+	Sort  string
+	Desc  bool
+	Limit int
+	After *OrderCursor // последний заказ предыдущей страницы
+}
+
+// OrderCursor позиция в списке заказов: значение поля сортировки и order_uid последнего заказа страницы
+type OrderCursor struct {
+	Sort        string    `json:"s"`
+	Desc        bool      `json:"d,omitempty"`
+	DateCreated time.Time `json:"t"`
+	Amount      int       `json:"a"`
+	OrderUID    uuid.UUID `json:"u"`
+}
+
+// NewOrderCursor курсор, указывающий на заказ order в списке с сортировкой filter
+func NewOrderCursor(order *Order, filter *OrderFilter) *OrderCursor {
+	return &OrderCursor{
+		Sort:        filter.Sort,
+		Desc:        filter.Desc,
+		DateCreated: order.DateCreated,
+		Amount:      order.Payment.Amount,
+		OrderUID:    order.OrderUID,
+	}
+}
+
+// Encode непрозрачное представление курсора для передачи клиенту
+func (c *OrderCursor) Encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeOrderCursor(s string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c OrderCursor
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// OrderPage Страница списка заказов
+// @Description Заказы страницы и курсор следующей страницы; next_cursor отсутствует на последней странице
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -9,20 +10,51 @@ import (
 // Order Полная модель заказа
 // @Descriptionn Модель описывает данные возвращаемого заказа
 type Order struct {
-	OrderUID          uuid.UUID `json:"order_uid" validate:"required"`
-	TrackNumber       string    `json:"track_number" validate:"required"`
-	Entry             string    `json:"entry" validate:"required"`
-	Delivery          Delivery  `json:"delivery" validate:"required"`
-	Payment           Payment   `json:"payment" validate:"required"`
-	Items             []Item    `json:"items" validate:"required"`
-	Locale            string    `json:"locale" validate:"required"`
-	InternalSignature string    `json:"internal_signature"`
-	CustomerID        string    `json:"customer_id" validate:"required"`
-	DeliveryService   string    `json:"delivery_service" validate:"required"`
-	Shardkey          string    `json:"shardkey" validate:"required"`
-	SmID              int       `json:"sm_id" validate:"gte=0"`
-	DateCreated       time.Time `json:"date_created" validate:"required"`
-	OofShard          string    `json:"oof_shard"`
+	OrderUID          uuid.UUID   `json:"order_uid" validate:"required"`
+	TrackNumber       string      `json:"track_number" validate:"required"`
+	Entry             string      `json:"entry" validate:"required"`
+	Delivery          Delivery    `json:"delivery" validate:"required"`
+	Payment           Payment     `json:"payment" validate:"required"`
+	Items             []Item      `json:"items" validate:"required,dive"`
+	Locale            string      `json:"locale" validate:"required"`
+	InternalSignature string      `json:"internal_signature"`
+	CustomerID        string      `json:"customer_id" validate:"required"`
+	DeliveryService   string      `json:"delivery_service" validate:"required"`
+	Shardkey          string      `json:"shardkey" validate:"required"`
+	SmID              int         `json:"sm_id" validate:"gte=0"`
+	DateCreated       time.Time   `json:"date_created" validate:"required"`
+	OofShard          string      `json:"oof_shard"`
+	Status            OrderStatus `json:"status,omitempty" validate:"omitempty,oneof=created paid assembling shipped delivered cancelled returned"`
+
+	// версия заказа у отправителя: заказ с версией или updated_at заменяет сохраненный, только если он новее
+	Version   int64      `json:"version,omitempty" validate:"gte=0"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// IsVersioned сообщает, что заказ пришел с версией и должен применяться как upsert
+func (o *Order) IsVersioned() bool {
+	return o.Version > 0 || o.UpdatedAt != nil
+}
+
+// IsNewerThan сообщает, что заказ новее сохраненного: сначала сравниваются версии,
+// при равных версиях - updated_at
+func (o *Order) IsNewerThan(stored *Order) bool {
+	if o.Version != stored.Version {
+		return o.Version > stored.Version
+	}
+	if o.UpdatedAt == nil {
+		return false
+	}
+	return stored.UpdatedAt == nil || o.UpdatedAt.After(*stored.UpdatedAt)
+}
+
+// CreatedAfter сообщает, что заказ создан позже other; при равных date_created больше order_uid.
+// В этом порядке БД отдает заказы покупателя и выбирает заказ по транзакции платежа
+func (o *Order) CreatedAfter(other *Order) bool {
+	if !o.DateCreated.Equal(other.DateCreated) {
+		return o.DateCreated.After(other.DateCreated)
+	}
+	return bytes.Compare(o.OrderUID.Bytes(), other.OrderUID.Bytes()) > 0
 }
 
 // Delivery Модель доставки
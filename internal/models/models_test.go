@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderIsNewerThan(t *testing.T) {
+	earlier := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Minute)
+
+	tests := []struct {
+		Name   string
+		Order  Order
+		Stored Order
+		Newer  bool
+	}{
+		{Name: "Higher_version", Order: Order{Version: 2}, Stored: Order{Version: 1}, Newer: true},
+		{Name: "Lower_version", Order: Order{Version: 1, UpdatedAt: &later}, Stored: Order{Version: 2, UpdatedAt: &earlier}, Newer: false},
+		{Name: "Same_version_later_update", Order: Order{Version: 1, UpdatedAt: &later}, Stored: Order{Version: 1, UpdatedAt: &earlier}, Newer: true},
+		{Name: "Same_version_same_update", Order: Order{Version: 1, UpdatedAt: &earlier}, Stored: Order{Version: 1, UpdatedAt: &earlier}, Newer: false},
+		{Name: "Same_version_without_update", Order: Order{Version: 1}, Stored: Order{Version: 1}, Newer: false},
+		{Name: "Stored_without_update", Order: Order{UpdatedAt: &earlier}, Stored: Order{}, Newer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Newer, tt.Order.IsNewerThan(&tt.Stored))
+		})
+	}
+}
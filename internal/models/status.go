@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// OrderStatus статус заказа
+type OrderStatus string
+
+const (
+	StatusCreated    OrderStatus = "created"
+	StatusPaid       OrderStatus = "paid"
+	StatusAssembling OrderStatus = "assembling"
+	StatusShipped    OrderStatus = "shipped"
+	StatusDelivered  OrderStatus = "delivered"
+	StatusCancelled  OrderStatus = "cancelled"
+	StatusReturned   OrderStatus = "returned"
+)
+
+// авторы изменений статуса, которые сервис делает сам
+const (
+	ActorSystem = "system" // начальный статус нового заказа
+	ActorUpsert = "upsert" // статус пришел вместе с новой версией заказа
+)
+
+// StatusChange Запись истории статусов заказа
+// @Description Модель описывает смену статуса заказа: откуда, куда, кто и когда
+type StatusChange struct {
+	OrderUID   uuid.UUID   `json:"order_uid"`
+	FromStatus OrderStatus `json:"from_status,omitempty"`
+	ToStatus   OrderStatus `json:"to_status"`
+	Actor      string      `json:"actor"`
+	ChangedAt  time.Time   `json:"changed_at"`
+}
+
+// StatusChangeRequest Запрос на смену статуса заказа
+// @Description Модель описывает новый статус заказа и того, кто его меняет
+type StatusChangeRequest struct {
+	OrderUID string      `json:"order_uid,omitempty"`
+	Status   OrderStatus `json:"status" validate:"required"`
+	Actor    string      `json:"actor" validate:"required"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// FieldError Ошибка проверки поля
+// @Description Поле в виде JSON пути (например items[1].sale), нарушенное правило, его параметр и описание
+type FieldError struct {
+	Field   string `json:"field" example:"items[1].sale"`
+	Rule    string `json:"rule" example:"lte"`
+	Param   string `json:"param,omitempty" example:"100"`
+	Message string `json:"message" example:"must be less than or equal to 100"`
+}
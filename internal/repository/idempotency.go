@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+// IdempotencyKeyTTL сколько хранится ключ идемпотентности; после этого ключ можно использовать заново
+const IdempotencyKeyTTL = 24 * time.Hour
+
+var (
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	ErrIdempotencyKeyExists   = errors.New("idempotency key is already in use")
+)
+
+// GetIdempotencyKey возвращает действующий ключ идемпотентности. Ключ читается с primary,
+// чтобы повтор запроса сразу после создания заказа его увидел
+func (r *OrderPostgresRepository) GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error) {
+	query := `SELECT key, request_hash, order_uid, created_at
+	FROM idempotency_key
+	WHERE key = $1 AND created_at > now() - $2::interval`
+
+	var k models.IdempotencyKey
+	err := r.Db.QueryRow(ctx, query, key, IdempotencyKeyTTL).Scan(&k.Key, &k.RequestHash, &k.OrderUID, &k.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("[GetIdempotencyKey| select]: , %w", ErrIdempotencyKeyNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[GetIdempotencyKey| select]: , %w", err)
+	}
+	return &k, nil
+}
+
+// saveIdempotencyKey запоминает в транзакции tx заказ, созданный по ключу. Истекший ключ перезаписывается.
+// Если ключ уже сохранил параллельный запрос, вставка ждет завершения его транзакции;
+// действующий ключ остается прежним, а возвращается ErrIdempotencyKeyExists
+func (r *OrderPostgresRepository) saveIdempotencyKey(ctx context.Context, tx pgx.Tx, k *models.IdempotencyKey) error {
+	query := `INSERT INTO idempotency_key(key, request_hash, order_uid)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (key) DO UPDATE
+	SET request_hash = EXCLUDED.request_hash, order_uid = EXCLUDED.order_uid, created_at = now()
+	WHERE idempotency_key.created_at <= now() - $4::interval`
+
+	tag, err := tx.Exec(ctx, query, k.Key, k.RequestHash, k.OrderUID, IdempotencyKeyTTL)
+	if err != nil {
+		return fmt.Errorf("[saveIdempotencyKey| exec insert]: , %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("[saveIdempotencyKey| key %q]: , %w", k.Key, ErrIdempotencyKeyExists)
+	}
+	return nil
+}
@@ -2,13 +2,41 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/database/postgres"
 	"github.com/orders_api/internal/models"
 )
 
 type OrderRepository interface {
 	GetOrderByUID(ctx context.Context, uid uuid.UUID) (*models.Order, error)
 	InsertOrder(ctx context.Context, order *models.Order) (*models.Order, error)
+	// InsertOrderWithKey вставляет заказ вместе с ключом идемпотентности в одной транзакции
+	InsertOrderWithKey(ctx context.Context, order *models.Order, key *models.IdempotencyKey) (*models.Order, error)
+	InsertOrders(ctx context.Context, orders []*models.Order) ([]error, error)
+	UpsertOrder(ctx context.Context, order *models.Order, checkStatus func(from, to models.OrderStatus) error) (bool, error)
+	// OnOrderChange регистрирует обработчик изменений сохраненных заказов
+	OnOrderChange(fn func(uid uuid.UUID))
 	GetAllOrders(ctx context.Context) ([]*models.Order, error)
+	ListOrders(ctx context.Context, filter *models.OrderFilter) ([]*models.Order, error)
+	// ExportOrders передает в fn порциями по batchSize все заказы, подходящие под filter, читая их серверным курсором
+	ExportOrders(ctx context.Context, filter *models.OrderFilter, batchSize int, fn func(orders []*models.Order) error) error
+	GetOrderByTrack(ctx context.Context, track string) (*models.Order, error)
+	GetOrderByTransaction(ctx context.Context, transaction uuid.UUID) (*models.Order, error)
+	GetOrdersByCustomer(ctx context.Context, customerID string, limit int, after *models.OrderCursor) ([]*models.Order, error)
+	GetChangedOrderUIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+
+	GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error)
+
+	GetOrderStatus(ctx context.Context, uid uuid.UUID) (models.OrderStatus, error)
+	UpdateOrderStatus(ctx context.Context, change *models.StatusChange) error
+	GetStatusHistory(ctx context.Context, uid uuid.UUID) ([]models.StatusChange, error)
+
+	// DBStats состояние пулов соединений с primary и репликами
+	DBStats() postgres.DBStats
+}
+
+type OutboxRepository interface {
+	ProcessOutbox(ctx context.Context, limit int, publish func([]models.OutboxEvent) error) (int, error)
 }
@@ -0,0 +1,348 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/interface.go
+//
+// Generated by this command:
+//
+//	mockgen --source=./internal/repository/interface.go --destination=./internal/repository/mocks/repository_mock.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/gofrs/uuid"
+	postgres "github.com/orders_api/internal/database/postgres"
+	models "github.com/orders_api/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderRepository is a mock of OrderRepository interface.
+type MockOrderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderRepositoryMockRecorder is the mock recorder for MockOrderRepository.
+type MockOrderRepositoryMockRecorder struct {
+	mock *MockOrderRepository
+}
+
+// NewMockOrderRepository creates a new mock instance.
+func NewMockOrderRepository(ctrl *gomock.Controller) *MockOrderRepository {
+	mock := &MockOrderRepository{ctrl: ctrl}
+	mock.recorder = &MockOrderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DBStats mocks base method.
+func (m *MockOrderRepository) DBStats() postgres.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DBStats")
+	ret0, _ := ret[0].(postgres.DBStats)
+	return ret0
+}
+
+// DBStats indicates an expected call of DBStats.
+func (mr *MockOrderRepositoryMockRecorder) DBStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DBStats", reflect.TypeOf((*MockOrderRepository)(nil).DBStats))
+}
+
+// ExportOrders mocks base method.
+func (m *MockOrderRepository) ExportOrders(ctx context.Context, filter *models.OrderFilter, batchSize int, fn func([]*models.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportOrders", ctx, filter, batchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportOrders indicates an expected call of ExportOrders.
+func (mr *MockOrderRepositoryMockRecorder) ExportOrders(ctx, filter, batchSize, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportOrders", reflect.TypeOf((*MockOrderRepository)(nil).ExportOrders), ctx, filter, batchSize, fn)
+}
+
+// GetAllOrders mocks base method.
+func (m *MockOrderRepository) GetAllOrders(ctx context.Context) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllOrders", ctx)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllOrders indicates an expected call of GetAllOrders.
+func (mr *MockOrderRepositoryMockRecorder) GetAllOrders(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrders", reflect.TypeOf((*MockOrderRepository)(nil).GetAllOrders), ctx)
+}
+
+// GetChangedOrderUIDs mocks base method.
+func (m *MockOrderRepository) GetChangedOrderUIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangedOrderUIDs", ctx, since)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangedOrderUIDs indicates an expected call of GetChangedOrderUIDs.
+func (mr *MockOrderRepositoryMockRecorder) GetChangedOrderUIDs(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedOrderUIDs", reflect.TypeOf((*MockOrderRepository)(nil).GetChangedOrderUIDs), ctx, since)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockOrderRepository) GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(*models.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockOrderRepositoryMockRecorder) GetIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockOrderRepository)(nil).GetIdempotencyKey), ctx, key)
+}
+
+// GetOrderByTrack mocks base method.
+func (m *MockOrderRepository) GetOrderByTrack(ctx context.Context, track string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByTrack", ctx, track)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByTrack indicates an expected call of GetOrderByTrack.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderByTrack(ctx, track any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByTrack", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByTrack), ctx, track)
+}
+
+// GetOrderByTransaction mocks base method.
+func (m *MockOrderRepository) GetOrderByTransaction(ctx context.Context, transaction uuid.UUID) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByTransaction", ctx, transaction)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByTransaction indicates an expected call of GetOrderByTransaction.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderByTransaction(ctx, transaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByTransaction", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByTransaction), ctx, transaction)
+}
+
+// GetOrderByUID mocks base method.
+func (m *MockOrderRepository) GetOrderByUID(ctx context.Context, uid uuid.UUID) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByUID", ctx, uid)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByUID indicates an expected call of GetOrderByUID.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderByUID(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByUID", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByUID), ctx, uid)
+}
+
+// GetOrderStatus mocks base method.
+func (m *MockOrderRepository) GetOrderStatus(ctx context.Context, uid uuid.UUID) (models.OrderStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatus", ctx, uid)
+	ret0, _ := ret[0].(models.OrderStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatus indicates an expected call of GetOrderStatus.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderStatus(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatus", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderStatus), ctx, uid)
+}
+
+// GetOrdersByCustomer mocks base method.
+func (m *MockOrderRepository) GetOrdersByCustomer(ctx context.Context, customerID string, limit int, after *models.OrderCursor) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersByCustomer", ctx, customerID, limit, after)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersByCustomer indicates an expected call of GetOrdersByCustomer.
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersByCustomer(ctx, customerID, limit, after any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByCustomer", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersByCustomer), ctx, customerID, limit, after)
+}
+
+// GetStatusHistory mocks base method.
+func (m *MockOrderRepository) GetStatusHistory(ctx context.Context, uid uuid.UUID) ([]models.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", ctx, uid)
+	ret0, _ := ret[0].([]models.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockOrderRepositoryMockRecorder) GetStatusHistory(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockOrderRepository)(nil).GetStatusHistory), ctx, uid)
+}
+
+// InsertOrder mocks base method.
+func (m *MockOrderRepository) InsertOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOrder", ctx, order)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOrder indicates an expected call of InsertOrder.
+func (mr *MockOrderRepositoryMockRecorder) InsertOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOrder", reflect.TypeOf((*MockOrderRepository)(nil).InsertOrder), ctx, order)
+}
+
+// InsertOrderWithKey mocks base method.
+func (m *MockOrderRepository) InsertOrderWithKey(ctx context.Context, order *models.Order, key *models.IdempotencyKey) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOrderWithKey", ctx, order, key)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOrderWithKey indicates an expected call of InsertOrderWithKey.
+func (mr *MockOrderRepositoryMockRecorder) InsertOrderWithKey(ctx, order, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOrderWithKey", reflect.TypeOf((*MockOrderRepository)(nil).InsertOrderWithKey), ctx, order, key)
+}
+
+// InsertOrders mocks base method.
+func (m *MockOrderRepository) InsertOrders(ctx context.Context, orders []*models.Order) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOrders", ctx, orders)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOrders indicates an expected call of InsertOrders.
+func (mr *MockOrderRepositoryMockRecorder) InsertOrders(ctx, orders any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOrders", reflect.TypeOf((*MockOrderRepository)(nil).InsertOrders), ctx, orders)
+}
+
+// ListOrders mocks base method.
+func (m *MockOrderRepository) ListOrders(ctx context.Context, filter *models.OrderFilter) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, filter)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderRepositoryMockRecorder) ListOrders(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderRepository)(nil).ListOrders), ctx, filter)
+}
+
+// OnOrderChange mocks base method.
+func (m *MockOrderRepository) OnOrderChange(fn func(uuid.UUID)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnOrderChange", fn)
+}
+
+// OnOrderChange indicates an expected call of OnOrderChange.
+func (mr *MockOrderRepositoryMockRecorder) OnOrderChange(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnOrderChange", reflect.TypeOf((*MockOrderRepository)(nil).OnOrderChange), fn)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, change *models.StatusChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderRepositoryMockRecorder) UpdateOrderStatus(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrderStatus), ctx, change)
+}
+
+// UpsertOrder mocks base method.
+func (m *MockOrderRepository) UpsertOrder(ctx context.Context, order *models.Order, checkStatus func(models.OrderStatus, models.OrderStatus) error) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrder", ctx, order, checkStatus)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrder indicates an expected call of UpsertOrder.
+func (mr *MockOrderRepositoryMockRecorder) UpsertOrder(ctx, order, checkStatus any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrder", reflect.TypeOf((*MockOrderRepository)(nil).UpsertOrder), ctx, order, checkStatus)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ProcessOutbox mocks base method.
+func (m *MockOutboxRepository) ProcessOutbox(ctx context.Context, limit int, publish func([]models.OutboxEvent) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessOutbox", ctx, limit, publish)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessOutbox indicates an expected call of ProcessOutbox.
+func (mr *MockOutboxRepositoryMockRecorder) ProcessOutbox(ctx, limit, publish any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOutbox", reflect.TypeOf((*MockOutboxRepository)(nil).ProcessOutbox), ctx, limit, publish)
+}
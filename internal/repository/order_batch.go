@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+// InsertOrders вставляет пачку заказов в одной транзакции.
+// Сначала пачка целиком загружается через COPY; если это не удалось (например, в пачке есть дубликат),
+// каждый заказ вставляется отдельно под своим savepoint, чтобы ошибочные строки не откатывали всю пачку.
+// Возвращает ошибки по каждому заказу (в порядке orders) и общую ошибку транзакции
+func (r *OrderPostgresRepository) InsertOrders(ctx context.Context, orders []*models.Order) ([]error, error) {
+	orderErrs := make([]error, len(orders))
+	if len(orders) == 0 {
+		return orderErrs, nil
+	}
+
+	tx, err := r.Db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[InsertOrders| begin transaction]: , %w", err)
+	}
+	// откат транзакции при ошибке в ней
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	copyErr := r.copyOrders(ctx, tx, orders)
+	if copyErr != nil {
+		slog.Debug("batch copy failed, inserting orders one by one", "error", copyErr, "orders", len(orders))
+
+		for i, order := range orders {
+			orderErrs[i] = r.insertOrderSavepoint(ctx, tx, order)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[InsertOrders| commit transaction]: , %w", err)
+	}
+	for i, order := range orders {
+		if orderErrs[i] == nil {
+			r.markWritten(order.OrderUID)
+		}
+	}
+	return orderErrs, nil
+}
+
+// insertOrderSavepoint вставляет заказ под savepoint: при ошибке откатывается только этот заказ
+func (r *OrderPostgresRepository) insertOrderSavepoint(ctx context.Context, tx pgx.Tx, order *models.Order) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("[insertOrderSavepoint| begin savepoint]: , %w", err)
+	}
+
+	err = r.insertFullOrder(ctx, sp, order)
+	if err != nil {
+		sp.Rollback(ctx)
+		return fmt.Errorf("[insertOrderSavepoint| insert order]: , %w", err)
+	}
+
+	err = sp.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("[insertOrderSavepoint| release savepoint]: , %w", err)
+	}
+	return nil
+}
+
+// copyOrders загружает все заказы пачки через COPY под savepoint.
+// Идентификаторы delivery и payment выделяются заранее из их последовательностей
+func (r *OrderPostgresRepository) copyOrders(ctx context.Context, tx pgx.Tx, orders []*models.Order) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("[copyOrders| begin savepoint]: , %w", err)
+	}
+	// откат savepoint при ошибке в нем
+	defer func() {
+		if err != nil {
+			sp.Rollback(ctx)
+		}
+	}()
+
+	deliveryIDs, err := r.nextIDs(ctx, sp, "delivery", "delivery_id", len(orders))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| next delivery ids]: , %w", err)
+	}
+
+	paymentIDs, err := r.nextIDs(ctx, sp, "payment", "payment_id", len(orders))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| next payment ids]: , %w", err)
+	}
+
+	_, err = sp.CopyFrom(ctx, pgx.Identifier{"delivery"},
+		[]string{"delivery_id", "name", "phone", "zip", "city", "address", "region", "email"},
+		pgx.CopyFromSlice(len(orders), func(i int) ([]any, error) {
+			del := &orders[i].Delivery
+			return []any{deliveryIDs[i], del.Name, del.Phone, del.Zip, del.City, del.Address, del.Region, del.Email}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| copy delivery]: , %w", err)
+	}
+
+	_, err = sp.CopyFrom(ctx, pgx.Identifier{"payment"},
+		[]string{"payment_id", "transaction", "request_id", "currency", "provider", "amount", "payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee"},
+		pgx.CopyFromSlice(len(orders), func(i int) ([]any, error) {
+			p := &orders[i].Payment
+			return []any{paymentIDs[i], p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount, p.PaymentDt, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| copy payment]: , %w", err)
+	}
+
+	_, err = sp.CopyFrom(ctx, pgx.Identifier{"order"},
+		[]string{"order_uid", "track_number", "entry", "delivery_id", "payment_id", "locale", "internal_signature", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "status", "version", "updated_at"},
+		pgx.CopyFromSlice(len(orders), func(i int) ([]any, error) {
+			o := orders[i]
+			return []any{o.OrderUID, o.TrackNumber, o.Entry, deliveryIDs[i], paymentIDs[i], o.Locale, o.InternalSignature, o.CustomerID, o.DeliveryService, o.Shardkey, o.SmID, o.DateCreated, o.OofShard, o.Status, o.Version, o.UpdatedAt}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| copy order]: , %w", err)
+	}
+
+	var items [][]any
+	for _, o := range orders {
+		for _, it := range o.Items {
+			items = append(items, []any{it.ChrtID, it.TrackNumber, it.Price, it.Rid, it.Name, it.Sale, it.Size, it.TotalPrice, it.NmID, it.Brand, it.Status})
+		}
+	}
+
+	_, err = sp.CopyFrom(ctx, pgx.Identifier{"item"},
+		[]string{"chrtid", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status"},
+		pgx.CopyFromRows(items))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| copy items]: , %w", err)
+	}
+
+	_, err = sp.CopyFrom(ctx, pgx.Identifier{"order_status_history"},
+		[]string{"order_uid", "to_status", "actor"},
+		pgx.CopyFromSlice(len(orders), func(i int) ([]any, error) {
+			return []any{orders[i].OrderUID, orders[i].Status, models.ActorSystem}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| copy status history]: , %w", err)
+	}
+
+	events := make([][]any, len(orders))
+	for i, o := range orders {
+		var event *models.OutboxEvent
+		event, err = newOutboxRow(models.EventOrderCreated, o)
+		if err != nil {
+			return fmt.Errorf("[copyOrders| new outbox event]: , %w", err)
+		}
+		events[i] = []any{event.EventID, event.Type, event.AggregateID, event.Payload}
+	}
+
+	_, err = sp.CopyFrom(ctx, pgx.Identifier{"outbox"},
+		[]string{"event_id", "event_type", "aggregate_id", "payload"},
+		pgx.CopyFromRows(events))
+	if err != nil {
+		return fmt.Errorf("[copyOrders| copy outbox events]: , %w", err)
+	}
+
+	err = sp.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("[copyOrders| release savepoint]: , %w", err)
+	}
+
+	// пачка записана, проставим выделенные идентификаторы в модели
+	for i, o := range orders {
+		o.Delivery.ID = deliveryIDs[i]
+		o.Payment.ID = paymentIDs[i]
+	}
+	return nil
+}
+
+// nextIDs выделяет n значений из последовательности serial-колонки таблицы
+func (r *OrderPostgresRepository) nextIDs(ctx context.Context, tx pgx.Tx, table, column string, n int) ([]int, error) {
+	query := `SELECT nextval(pg_get_serial_sequence($1, $2)) FROM generate_series(1, $3)`
+
+	rows, err := tx.Query(ctx, query, table, column, n)
+	if err != nil {
+		return nil, fmt.Errorf("[nextIDs| query]: , %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("[nextIDs| rows scan]: , %w", err)
+	}
+	return ids, nil
+}
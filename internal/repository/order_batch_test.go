@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchOrders заказы для записи пачкой, удаляются после теста
+func newBatchOrders(t *testing.T, r *OrderPostgresRepository, n int) []*models.Order {
+	orders := make([]*models.Order, n)
+	for i := range orders {
+		orders[i] = newBenchOrder(i)
+	}
+	t.Cleanup(func() {
+		for _, o := range orders {
+			deleteTestOrder(t, r, o.OrderUID)
+		}
+	})
+	return orders
+}
+
+func TestInsertOrders_Copy(t *testing.T) {
+	r := NewOrderPostgresRepository(newDBPool(t, nil))
+	ctx := context.Background()
+	orders := newBatchOrders(t, r, 3)
+
+	orderErrs, err := r.InsertOrders(ctx, orders)
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, nil, nil}, orderErrs)
+
+	for _, o := range orders {
+		stored, err := r.GetOrderByUID(ctx, o.OrderUID)
+		require.NoError(t, err)
+		assert.Equal(t, o.Payment.ID, stored.Payment.ID)
+		assert.Len(t, stored.Items, len(o.Items))
+	}
+}
+
+func TestInsertOrders_SavepointPerOrder(t *testing.T) {
+	r := NewOrderPostgresRepository(newDBPool(t, nil))
+	ctx := context.Background()
+
+	saved := newBatchOrders(t, r, 1)
+	_, err := r.InsertOrders(ctx, saved)
+	require.NoError(t, err)
+
+	// повтор сохраненного заказа и заказ с занятым track_number ломают COPY всей пачки
+	orders := newBatchOrders(t, r, 2)
+	sameUID := newBenchOrder(0)
+	sameUID.OrderUID = saved[0].OrderUID
+	sameTrack := newBenchOrder(0)
+	sameTrack.OrderUID = uuid.Must(uuid.NewV4())
+	sameTrack.TrackNumber = saved[0].TrackNumber
+	batch := []*models.Order{orders[0], sameUID, sameTrack, orders[1]}
+
+	orderErrs, err := r.InsertOrders(ctx, batch)
+	require.NoError(t, err)
+	require.Len(t, orderErrs, len(batch))
+	assert.NoError(t, orderErrs[0])
+	assert.ErrorIs(t, orderErrs[1], ErrOrderAlreadyExistsUUID)
+	assert.ErrorIs(t, orderErrs[2], ErrOrderAlreadyExistsTrack)
+	assert.NoError(t, orderErrs[3])
+
+	// откатились только ошибочные заказы: остальные записаны в той же транзакции
+	for _, o := range orders {
+		_, err := r.GetOrderByUID(ctx, o.OrderUID)
+		assert.NoError(t, err)
+	}
+	_, err = r.GetOrderByUID(ctx, sameTrack.OrderUID)
+	assert.ErrorIs(t, err, ErrOrderNotFoundByUUID)
+
+	stored, err := r.GetOrderByUID(ctx, saved[0].OrderUID)
+	require.NoError(t, err)
+	assert.Equal(t, saved[0].Payment.Amount, stored.Payment.Amount)
+}
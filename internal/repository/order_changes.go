@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+// GetChangedOrderUIDs возвращает заказы, которые после since меняли статус, заменялись новой версией или удалялись
+func (r *OrderPostgresRepository) GetChangedOrderUIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	query := `SELECT order_uid FROM order_status_history WHERE changed_at > $1
+	UNION
+	SELECT aggregate_id FROM outbox WHERE created_at > $1 AND event_type <> $2`
+
+	rows, err := r.Db.Query(ctx, query, since, models.EventOrderCreated)
+	if err != nil {
+		return nil, fmt.Errorf("[GetChangedOrderUIDs|query]: , %w", err)
+	}
+
+	uids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("[GetChangedOrderUIDs|rows scan uid]: , %w", err)
+	}
+	return uids, nil
+}
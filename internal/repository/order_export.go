@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+// exportCursor имя серверного курсора выгрузки, уникально в пределах транзакции
+const exportCursor = "orders_export"
+
+// ExportOrders читает все заказы, подходящие под фильтры filter, через серверный курсор и передает их в fn порциями по batchSize.
+// Чтение идет одной транзакцией с уровнем repeatable read, поэтому порции согласованы между собой,
+// а в памяти одновременно находится только одна порция. filter.Limit и filter.After не учитываются
+func (r *OrderPostgresRepository) ExportOrders(ctx context.Context, filter *models.OrderFilter, batchSize int, fn func(orders []*models.Order) error) error {
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatch
+	}
+
+	exportFilter := *filter
+	exportFilter.Limit = 0
+	exportFilter.After = nil
+	clause, args := orderListClause(&exportFilter)
+
+	tx, err := r.reader(ctx).BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("[ExportOrders| begin tx]: , %w", err)
+	}
+	// транзакция только читает, курсор закрывается вместе с ней
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DECLARE `+exportCursor+` NO SCROLL CURSOR FOR `+selectOrdersQuery+clause, args...)
+	if err != nil {
+		return fmt.Errorf("[ExportOrders| declare cursor]: , %w", err)
+	}
+
+	fetch := fmt.Sprintf(`FETCH FORWARD %d FROM %s`, batchSize, exportCursor)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("[ExportOrders| fetch orders]: , %w", err)
+		}
+		orders, err := scanOrders(rows)
+		if err != nil {
+			return fmt.Errorf("[ExportOrders| scan orders]: , %w", err)
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		err = loadItems(ctx, tx, orders)
+		if err != nil {
+			return fmt.Errorf("[ExportOrders| load items]: , %w", err)
+		}
+
+		err = fn(orders)
+		if err != nil {
+			return fmt.Errorf("[ExportOrders| handle orders]: , %w", err)
+		}
+		if len(orders) < batchSize {
+			return nil
+		}
+	}
+}
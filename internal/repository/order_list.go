@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+// ListOrders возвращает до filter.Limit заказов, подходящих под фильтры, начиная после filter.After
+func (r *OrderPostgresRepository) ListOrders(ctx context.Context, filter *models.OrderFilter) ([]*models.Order, error) {
+	clause, args := orderListClause(filter)
+	orders, err := r.selectOrders(ctx, clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[ListOrders| select orders]: , %w", err)
+	}
+	return orders, nil
+}
+
+// orderListClause собирает условия, сортировку и ограничение выборки списка заказов для selectOrders.
+// Limit 0 не ограничивает выборку
+func orderListClause(filter *models.OrderFilter) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	// arg добавляет аргумент запроса и возвращает его плейсхолдер
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	eq := []struct {
+		column string
+		value  string
+	}{
+		{"o.customer_id", filter.CustomerID},
+		{"o.delivery_service", filter.DeliveryService},
+		{"o.entry", filter.Entry},
+		{"o.locale", filter.Locale},
+		{"p.currency", filter.Currency},
+		{"p.provider", filter.Provider},
+		{"p.bank", filter.Bank},
+	}
+	for _, f := range eq {
+		if f.value != "" {
+			conds = append(conds, f.column+" = "+arg(f.value))
+		}
+	}
+
+	if filter.DateFrom != nil {
+		conds = append(conds, "o.date_created >= "+arg(*filter.DateFrom))
+	}
+	if filter.DateTo != nil {
+		conds = append(conds, "o.date_created < "+arg(*filter.DateTo))
+	}
+	if filter.AmountMin != nil {
+		conds = append(conds, "p.amount >= "+arg(*filter.AmountMin))
+	}
+	if filter.AmountMax != nil {
+		conds = append(conds, "p.amount <= "+arg(*filter.AmountMax))
+	}
+
+	sortColumn := "o.date_created"
+	if filter.Sort == models.SortByAmount {
+		sortColumn = "p.amount"
+	}
+	direction, cmp := "ASC", ">"
+	if filter.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	// keyset пагинация: order_uid делает порядок однозначным при равных значениях поля сортировки
+	if c := filter.After; c != nil {
+		var sortValue any = c.DateCreated
+		if filter.Sort == models.SortByAmount {
+			sortValue = c.Amount
+		}
+		conds = append(conds, fmt.Sprintf("(%s, o.order_uid) %s (%s, %s)", sortColumn, cmp, arg(sortValue), arg(c.OrderUID)))
+	}
+
+	var clause string
+	if len(conds) > 0 {
+		clause = `WHERE ` + strings.Join(conds, " AND ")
+	}
+	clause += fmt.Sprintf(`
+	ORDER BY %s %s, o.order_uid %s`, sortColumn, direction, direction)
+	if filter.Limit > 0 {
+		clause += `
+	LIMIT ` + arg(filter.Limit)
+	}
+	return clause, args
+}
+
+// selectOrders читает заказы вместе с delivery и payment одним запросом, items - одним запросом на все заказы.
+// clause - условия, сортировка и ограничение выборки; в нем доступны алиасы o (order), d (delivery) и p (payment).
+// Оба запроса выполняются на одном сервере, выбранном для чтения
+func (r *OrderPostgresRepository) selectOrders(ctx context.Context, clause string, args ...any) ([]*models.Order, error) {
+	db := r.reader(ctx)
+	rows, err := db.Query(ctx, selectOrdersQuery+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[selectOrders|rows scan]: , %w", err)
+	}
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("[selectOrders|scan orders]: , %w", err)
+	}
+
+	err = loadItems(ctx, db, orders)
+	if err != nil {
+		return nil, fmt.Errorf("[selectOrders|load items]: , %w", err)
+	}
+	return orders, nil
+}
+
+// querier выполняет запросы на пуле соединений или в транзакции
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// scanOrders читает заказы без items из строк запроса selectOrdersQuery и закрывает rows
+func scanOrders(rows pgx.Rows) ([]*models.Order, error) {
+	defer rows.Close()
+
+	orders := []*models.Order{}
+	for rows.Next() {
+		var o models.Order
+		d, p := &o.Delivery, &o.Payment
+
+		err := rows.Scan(&o.OrderUID, &o.TrackNumber, &o.Entry, &d.ID, &p.ID, &o.Locale, &o.InternalSignature, &o.CustomerID, &o.DeliveryService, &o.Shardkey, &o.SmID, &o.DateCreated, &o.OofShard, &o.Status, &o.Version, &o.UpdatedAt,
+			&d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email,
+			&p.Transaction, &p.RequestID, &p.Currency, &p.Provider, &p.Amount, &p.PaymentDt, &p.Bank, &p.DeliveryCost, &p.GoodsTotal, &p.CustomFee)
+		if err != nil {
+			return nil, fmt.Errorf("[scanOrders|row scan order]: , %w", err)
+		}
+		orders = append(orders, &o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[scanOrders|rows scan]: , %w", err)
+	}
+	return orders, nil
+}
+
+// loadItems загружает items всех заказов orders одним запросом
+func loadItems(ctx context.Context, db querier, orders []*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	tracks := make([]string, 0, len(orders))
+	byTrack := make(map[string]*models.Order, len(orders))
+	for _, o := range orders {
+		tracks = append(tracks, o.TrackNumber)
+		byTrack[o.TrackNumber] = o
+	}
+
+	query := `SELECT chrtID,track_number,price,rid,name,sale,size,total_price,nm_id,brand,status FROM item
+	WHERE track_number = ANY($1)
+	ORDER BY item_id`
+
+	itemRows, err := db.Query(ctx, query, tracks)
+	if err != nil {
+		return fmt.Errorf("[loadItems|rows scan items]: , %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var it models.Item
+		err = itemRows.Scan(&it.ChrtID, &it.TrackNumber, &it.Price, &it.Rid, &it.Name, &it.Sale, &it.Size, &it.TotalPrice, &it.NmID, &it.Brand, &it.Status)
+		if err != nil {
+			return fmt.Errorf("[loadItems|row scan item]: , %w", err)
+		}
+
+		o := byTrack[it.TrackNumber]
+		o.Items = append(o.Items, it)
+	}
+	if err = itemRows.Err(); err != nil {
+		return fmt.Errorf("[loadItems|rows scan items]: , %w", err)
+	}
+	return nil
+}
+
+// selectOrdersQuery запрос заказов вместе с delivery и payment без условий выборки
+const selectOrdersQuery = `SELECT ` + listOrderColumns + `
+	FROM "order" o
+	JOIN delivery d ON d.delivery_id = o.delivery_id
+	JOIN payment p ON p.payment_id = o.payment_id
+	`
+
+// listOrderColumns колонки заказа, delivery и payment в порядке, который ожидает selectOrders
+const listOrderColumns = `o.order_uid,o.track_number,o.entry,o.delivery_id,o.payment_id,o.locale,o.internal_signature,o.customer_id,o.delivery_service,o.shardkey,o.sm_id,o.date_created,o.oof_shard,o.status,o.version,o.updated_at,
+	d.name,d.phone,d.zip,d.city,d.address,d.region,d.email,
+	p.transaction,p.request_id,p.currency,p.provider,p.amount,p.payment_dt,p.bank,p.delivery_cost,p.goods_total,p.custom_fee`
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+)
+
+var (
+	ErrOrderNotFoundByTrack       = errors.New("order with this track_number not found")
+	ErrOrderNotFoundByTransaction = errors.New("order with this payment transaction not found")
+)
+
+func (r *OrderPostgresRepository) GetOrderByTrack(ctx context.Context, track string) (*models.Order, error) {
+	orders, err := r.selectOrders(ctx, `WHERE o.track_number = $1`, track)
+	if err != nil {
+		return nil, fmt.Errorf("[GetOrderByTrack| select orders]: , %w", err)
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("[GetOrderByTrack| select orders]: , %w", ErrOrderNotFoundByTrack)
+	}
+	return orders[0], nil
+}
+
+// GetOrderByTransaction возвращает заказ по транзакции платежа. Транзакция не уникальна:
+// если ей оплачено несколько заказов, возвращается самый новый из них
+func (r *OrderPostgresRepository) GetOrderByTransaction(ctx context.Context, transaction uuid.UUID) (*models.Order, error) {
+	orders, err := r.selectOrders(ctx, `WHERE p.transaction = $1
+	ORDER BY o.date_created DESC, o.order_uid DESC
+	LIMIT 1`, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("[GetOrderByTransaction| select orders]: , %w", err)
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("[GetOrderByTransaction| select orders]: , %w", ErrOrderNotFoundByTransaction)
+	}
+	return orders[0], nil
+}
+
+// GetOrdersByCustomer возвращает не больше limit заказов покупателя после курсора after, новые первыми.
+// Пустой after - первая страница
+func (r *OrderPostgresRepository) GetOrdersByCustomer(ctx context.Context, customerID string, limit int, after *models.OrderCursor) ([]*models.Order, error) {
+	clause, args := orderListClause(&models.OrderFilter{
+		CustomerID: customerID,
+		Sort:       models.SortByDateCreated,
+		Desc:       true,
+		Limit:      limit,
+		After:      after,
+	})
+	orders, err := r.selectOrders(ctx, clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("[GetOrdersByCustomer| select orders]: , %w", err)
+	}
+	return orders, nil
+}
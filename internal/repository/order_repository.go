@@ -8,6 +8,8 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/orders_api/internal/database/postgres"
 	"github.com/orders_api/internal/models"
 )
 
@@ -18,67 +20,74 @@ var (
 )
 
 type OrderPostgresRepository struct {
-	Db *pgx.Conn
+	Db *pgxpool.Pool
+
+	// обработчики изменений сохраненных заказов
+	changeHooks []func(uid uuid.UUID)
+
+	// реплики для чтения и недавно измененные заказы, которые читаются с primary
+	replicas ReplicaSet
+	recent   *recentWrites
 }
 
-func NewOrderPostgresRepository(db *pgx.Conn) *OrderPostgresRepository {
+func NewOrderPostgresRepository(db *pgxpool.Pool) *OrderPostgresRepository {
 	return &OrderPostgresRepository{
 		Db: db,
 	}
 }
 
-func (r *OrderPostgresRepository) GetOrderByUID(ctx context.Context, uid uuid.UUID) (*models.Order, error) {
-
-	tx, err := r.Db.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("[GetOrderByUID| begin transaction]: , %w", err)
+func (r *OrderPostgresRepository) DBStats() postgres.DBStats {
+	stats := postgres.DBStats{Primary: postgres.Stats(r.Db)}
+	if r.replicas != nil {
+		stats.Replicas = r.replicas.Stats()
 	}
-	// откат транзакции при ошибке в ней
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		}
-	}()
-
-	// найдем сам заказ ( если он есть )
-	respOrder, err := r.getOrder(ctx, uid, tx)
-	if err != nil {
-		return nil, fmt.Errorf("[GetOrderByUID| get order]: , %w", err)
+	return stats
+}
 
-	}
+// OnOrderChange регистрирует обработчик, который вызывается после фиксации изменения сохраненного заказа.
+// Обработчики регистрируются до начала работы с репозиторием
+func (r *OrderPostgresRepository) OnOrderChange(fn func(uid uuid.UUID)) {
+	r.changeHooks = append(r.changeHooks, fn)
+}
 
-	// подтянем данные о Delivery ( по delivery_id )
-	del, err := r.getDelivery(ctx, respOrder.Delivery.ID, tx)
-	if err != nil {
-		return nil, fmt.Errorf("[GetOrderByUID| get delivery]: , %w", err)
+func (r *OrderPostgresRepository) notifyChange(uid uuid.UUID) {
+	r.markWritten(uid)
+	for _, hook := range r.changeHooks {
+		hook(uid)
 	}
-	respOrder.Delivery = *del
+}
 
-	// подтянем данные о Payment ( по payment_id )
-	pay, err := r.getPayment(ctx, respOrder.Payment.ID, tx)
+// GetOrderByUID читает заказ вместе с delivery и payment одним запросом, items - вторым
+func (r *OrderPostgresRepository) GetOrderByUID(ctx context.Context, uid uuid.UUID) (*models.Order, error) {
+	ctx = r.freshRead(ctx, uid)
+	orders, err := r.selectOrders(ctx, `WHERE o.order_uid = $1`, uid)
 	if err != nil {
-		return nil, fmt.Errorf("[GetOrderByUID| get payment]: , %w", err)
+		return nil, fmt.Errorf("[GetOrderByUID| select order]: , %w", err)
 	}
-	respOrder.Payment = *pay
-
-	// подтянем данные о items ( по track_number )
-	items, err := r.getItems(ctx, respOrder.TrackNumber, tx)
-	if err != nil {
-		return nil, fmt.Errorf("[GetOrderByUID| get items]: , %w", err)
+	// заказ мог быть записан другим экземпляром сервиса и еще не дойти до реплики
+	if len(orders) == 0 && r.readsFromReplica(ctx) {
+		orders, err = r.selectOrders(ReadFromPrimary(ctx), `WHERE o.order_uid = $1`, uid)
+		if err != nil {
+			return nil, fmt.Errorf("[GetOrderByUID| select order from primary]: , %w", err)
+		}
 	}
-	respOrder.Items = items
-
-	err = tx.Commit(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("[GetOrderByUID| commit transaction]: , %w", err)
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("[GetOrderByUID| select order]: , %w", ErrOrderNotFoundByUUID)
 	}
-	return respOrder, nil
+	return orders[0], nil
 }
 
 func (r *OrderPostgresRepository) InsertOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
+	return r.InsertOrderWithKey(ctx, order, nil)
+}
+
+// InsertOrderWithKey вставляет заказ и ключ идемпотентности key, по которому он создан, в одной транзакции,
+// поэтому созданный заказ всегда можно найти по ключу. Если действующий ключ уже сохранен, заказ не вставляется
+// и возвращается ErrIdempotencyKeyExists. key = nil - вставка без ключа
+func (r *OrderPostgresRepository) InsertOrderWithKey(ctx context.Context, order *models.Order, key *models.IdempotencyKey) (*models.Order, error) {
 	tx, err := r.Db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("[InsertOrder| begin transaction]: , %w", err)
+		return nil, fmt.Errorf("[InsertOrderWithKey| begin transaction]: , %w", err)
 	}
 	// откат транзакции при ошибке в ней
 	defer func() {
@@ -87,10 +96,33 @@ func (r *OrderPostgresRepository) InsertOrder(ctx context.Context, order *models
 		}
 	}()
 
+	err = r.insertFullOrder(ctx, tx, order)
+	if err != nil {
+		return nil, fmt.Errorf("[InsertOrderWithKey| insert in transaction]: , %w", err)
+	}
+
+	// ключ ссылается на заказ, поэтому сохраняется после него
+	if key != nil {
+		err = r.saveIdempotencyKey(ctx, tx, key)
+		if err != nil {
+			return nil, fmt.Errorf("[InsertOrderWithKey| save idempotency key]: , %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[InsertOrderWithKey| commit transaction]: , %w", err)
+	}
+	r.markWritten(order.OrderUID)
+	return order, nil
+}
+
+// insertFullOrder вставляет заказ вместе с delivery, payment и items в рамках транзакции tx
+func (r *OrderPostgresRepository) insertFullOrder(ctx context.Context, tx pgx.Tx, order *models.Order) error {
 	// вставляем данные о Delivery
 	delivery_id, err := r.insertDelivery(ctx, tx, &order.Delivery)
 	if err != nil {
-		return nil, fmt.Errorf("[InsertOrder| insert delivery in transaction]: , %w", err)
+		return fmt.Errorf("[insertFullOrder| insert delivery]: , %w", err)
 
 	}
 	order.Delivery.ID = delivery_id
@@ -98,57 +130,73 @@ func (r *OrderPostgresRepository) InsertOrder(ctx context.Context, order *models
 	// вставляем данные о Payment
 	payment_id, err := r.insertPayment(ctx, tx, &order.Payment)
 	if err != nil {
-		return nil, fmt.Errorf("[InsertOrder| insert payment in transaction]: , %w", err)
+		return fmt.Errorf("[insertFullOrder| insert payment]: , %w", err)
 	}
 	order.Payment.ID = payment_id
 
 	// вставляем данные самого заказа
 	err = r.insertOrder(ctx, tx, order)
 	if err != nil {
-		return nil, fmt.Errorf("[InsertOrder| insert order in transaction]: , %w", err)
+		return fmt.Errorf("[insertFullOrder| insert order]: , %w", err)
 	}
 
 	// вставляем данные о Items
 	err = r.insertItems(ctx, tx, &order.Items)
 	if err != nil {
-		return nil, fmt.Errorf("[InsertOrder| insert items in transaction]: , %w", err)
+		return fmt.Errorf("[insertFullOrder| insert items]: , %w", err)
 	}
 
-	err = tx.Commit(ctx)
+	// начальный статус заказа - первая запись в истории статусов
+	err = r.insertStatusHistory(ctx, tx, &models.StatusChange{
+		OrderUID: order.OrderUID,
+		ToStatus: order.Status,
+		Actor:    models.ActorSystem,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("[InsertOrder| commit transaction]: , %w", err)
+		return fmt.Errorf("[insertFullOrder| insert status history]: , %w", err)
 	}
-	return order, nil
+
+	// событие о создании заказа публикуется только после коммита транзакции
+	err = r.insertOutboxEvent(ctx, tx, models.EventOrderCreated, order)
+	if err != nil {
+		return fmt.Errorf("[insertFullOrder| insert outbox event]: , %w", err)
+	}
+	return nil
 }
 
 func (r *OrderPostgresRepository) insertOrder(ctx context.Context, tx pgx.Tx, order *models.Order) error {
-	query := `INSERT INTO "order" ( order_uid,track_number,entry,delivery_id,payment_id,locale,internal_signature,customer_id,delivery_service,shardkey,sm_id,date_created, oof_shard) 
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`
+	query := `INSERT INTO "order" ( order_uid,track_number,entry,delivery_id,payment_id,locale,internal_signature,customer_id,delivery_service,shardkey,sm_id,date_created, oof_shard,status,version,updated_at) 
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
 
-	_, err := tx.Exec(ctx, query, order.OrderUID, order.TrackNumber, order.Entry, order.Delivery.ID, order.Payment.ID, order.Locale, order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard)
+	_, err := tx.Exec(ctx, query, order.OrderUID, order.TrackNumber, order.Entry, order.Delivery.ID, order.Payment.ID, order.Locale, order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Status, order.Version, order.UpdatedAt)
 
 	if err != nil {
-		// проверим, связана ли ошибка с тем что заказ с уникальным полем уже есть
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			// проверим по какому полю ошибка уникальности
-			// проверять будем по именам constraint, для поля track_number будет присвоего дефолтное имя order_track_number_key
-			switch {
-			case pgErr.ConstraintName == "order_track_number_key":
-				return fmt.Errorf("[insertOrder| exec insert order]: , %w", ErrOrderAlreadyExistsTrack)
-
-			default:
-				return fmt.Errorf("[insertOrder| exec insert order]: , %w", ErrOrderAlreadyExistsUUID)
-			}
-		}
-
-		// если ошибка не вызвана дублированием уникального поля
-		return fmt.Errorf("[insertOrder| exec insert order]: , %w", err)
+		return fmt.Errorf("[insertOrder| exec insert order]: , %w", uniqueViolation(err))
 	}
 
 	return nil
 }
 
+// uniqueViolation переводит ошибку уникальности заказа в ошибку репозитория, остальные ошибки возвращает как есть
+func uniqueViolation(err error) error {
+	// проверим, связана ли ошибка с тем что заказ с уникальным полем уже есть
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// проверим по какому полю ошибка уникальности
+		// проверять будем по именам constraint, для поля track_number будет присвоего дефолтное имя order_track_number_key
+		switch {
+		case pgErr.ConstraintName == "order_track_number_key":
+			return ErrOrderAlreadyExistsTrack
+
+		default:
+			return ErrOrderAlreadyExistsUUID
+		}
+	}
+
+	// если ошибка не вызвана дублированием уникального поля
+	return err
+}
+
 func (r *OrderPostgresRepository) insertDelivery(ctx context.Context, tx pgx.Tx, del *models.Delivery) (int, error) {
 	query := `INSERT INTO delivery(
 	name,phone,zip,city,address,region,email) 
@@ -210,39 +258,11 @@ func (r *OrderPostgresRepository) insertItems(ctx context.Context, tx pgx.Tx, it
 	return nil
 }
 
-func (r *OrderPostgresRepository) getOrder(ctx context.Context, id uuid.UUID, tx pgx.Tx) (*models.Order, error) {
-	var responceOrder models.Order
+// orderColumns колонки таблицы order в порядке, который ожидает scanOrder
+const orderColumns = `order_uid,track_number,entry,delivery_id,payment_id,locale,internal_signature,customer_id,delivery_service,shardkey,sm_id,date_created,oof_shard,status,version,updated_at`
 
-	query := `SELECT *
-	FROM "order" 
-	WHERE order_uid = $1`
-
-	row := tx.QueryRow(ctx, query, id)
-
-	err := row.Scan(&responceOrder.OrderUID, &responceOrder.TrackNumber, &responceOrder.Entry, &responceOrder.Delivery.ID, &responceOrder.Payment.ID, &responceOrder.Locale, &responceOrder.InternalSignature, &responceOrder.CustomerID, &responceOrder.DeliveryService, &responceOrder.Shardkey, &responceOrder.SmID, &responceOrder.DateCreated, &responceOrder.OofShard)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("[getOrder|row scan]: , %w", ErrOrderNotFoundByUUID)
-		}
-		return nil, fmt.Errorf("[getOrder|row scan]: , %w", err)
-	}
-	return &responceOrder, nil
-}
-
-func (r *OrderPostgresRepository) getDelivery(ctx context.Context, id int, tx pgx.Tx) (*models.Delivery, error) {
-	var del models.Delivery
-
-	query := `SELECT name,phone,zip,city,address,region,email
-	FROM delivery 
-	WHERE delivery_id = $1`
-
-	err := tx.QueryRow(ctx, query, id).Scan(&del.Name, &del.Phone, &del.Zip, &del.City, &del.Address, &del.Region, &del.Email)
-
-	if err != nil {
-		return nil, fmt.Errorf("[getDelivery|row scan]: , %w", err)
-	}
-	return &del, nil
+func scanOrder(row pgx.Row, o *models.Order) error {
+	return row.Scan(&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Delivery.ID, &o.Payment.ID, &o.Locale, &o.InternalSignature, &o.CustomerID, &o.DeliveryService, &o.Shardkey, &o.SmID, &o.DateCreated, &o.OofShard, &o.Status, &o.Version, &o.UpdatedAt)
 }
 
 func (r *OrderPostgresRepository) getPayment(ctx context.Context, id int, tx pgx.Tx) (*models.Payment, error) {
@@ -262,88 +282,16 @@ func (r *OrderPostgresRepository) getPayment(ctx context.Context, id int, tx pgx
 	return &pay, nil
 }
 
-func (r *OrderPostgresRepository) getItems(ctx context.Context, track string, tx pgx.Tx) ([]models.Item, error) {
-	var items []models.Item
-
-	query := `SELECT chrtID,track_number,price,rid,name,sale,size,total_price,nm_id,brand,status FROM item 
-	WHERE track_number = $1`
-
-	rows, err := tx.Query(ctx, query, track)
-	if err != nil {
-		return nil, fmt.Errorf("[getItems|rows scan]: , %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var it models.Item
-		err := rows.Scan(&it.ChrtID, &it.TrackNumber, &it.Price, &it.Rid, &it.Name, &it.Sale, &it.Size, &it.TotalPrice, &it.NmID, &it.Brand, &it.Status)
-		if err != nil {
-			return nil, fmt.Errorf("[getItems|rows scan item]: , %w", err)
-		}
-
-		items = append(items, it)
-	}
-
-	return items, nil
-}
-
+// GetAllOrders возвращает все заказы. Для больших таблиц следует использовать StreamOrders
 func (r *OrderPostgresRepository) GetAllOrders(ctx context.Context) ([]*models.Order, error) {
 	orders := []*models.Order{}
 
-	tx, err := r.Db.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("[GetAllOrders| begin transaction]: , %w", err)
-	}
-	// откат транзакции при ошибке в ней
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		}
-	}()
-
-	// получим массив заказов, заполним только данными самих заказов
-	query := `SELECT * FROM "order"`
-	rows, err := tx.Query(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("[GetAllOrders|rows scan]: , %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var curOrder models.Order
-		err = rows.Scan(&curOrder.OrderUID, &curOrder.TrackNumber, &curOrder.Entry, &curOrder.Delivery.ID, &curOrder.Payment.ID, &curOrder.Locale, &curOrder.InternalSignature, &curOrder.CustomerID, &curOrder.DeliveryService, &curOrder.Shardkey, &curOrder.SmID, &curOrder.DateCreated, &curOrder.OofShard)
-
-		if err != nil {
-			return nil, fmt.Errorf("[GetAllOrders|row scan order]: , %w", err)
-		}
-
-		orders = append(orders, &curOrder)
-	}
-
-	// пройдемся по массиву, для каждого заказа найдем соответствующие delivery,payment и items и подтянем информацию о них
-	for _, curOrder := range orders {
-		curDelivery, err := r.getDelivery(ctx, curOrder.Delivery.ID, tx)
-		if err != nil {
-			return nil, fmt.Errorf("[GetAllOrders| get delivery]: , %w", err)
-		}
-		curOrder.Delivery = *curDelivery
-
-		curPayment, err := r.getPayment(ctx, curOrder.Payment.ID, tx)
-		if err != nil {
-			return nil, fmt.Errorf("[GetAllOrders| get payment]: , %w", err)
-		}
-		curOrder.Payment = *curPayment
-
-		curItems, err := r.getItems(ctx, curOrder.TrackNumber, tx)
-		if err != nil {
-			return nil, fmt.Errorf("[GetAllOrders| get items]: , %w", err)
-		}
-		curOrder.Items = curItems
-	}
-
-	err = tx.Commit(ctx)
+	err := r.StreamOrders(ctx, defaultStreamBatch, func(batch []*models.Order) error {
+		orders = append(orders, batch...)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("[GetAllOrders| commit transaction]: , %w", err)
+		return nil, fmt.Errorf("[GetAllOrders| stream orders]: , %w", err)
 	}
 	return orders, nil
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orders_api/internal/models"
+)
+
+// defaultStreamBatch сколько заказов читать за раз, если размер порции не задан
+const defaultStreamBatch = 1000
+
+// StreamOrders читает все заказы порциями по batchSize в порядке date_created и передает каждую порцию в fn.
+// В памяти одновременно находится только одна порция, каждая читается двумя запросами; ошибка fn прерывает чтение
+func (r *OrderPostgresRepository) StreamOrders(ctx context.Context, batchSize int, fn func(orders []*models.Order) error) error {
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatch
+	}
+
+	filter := &models.OrderFilter{
+		Sort:  models.SortByDateCreated,
+		Limit: batchSize,
+	}
+	for {
+		orders, err := r.ListOrders(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("[StreamOrders| list orders]: , %w", err)
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		err = fn(orders)
+		if err != nil {
+			return fmt.Errorf("[StreamOrders| handle orders]: , %w", err)
+		}
+		if len(orders) < batchSize {
+			return nil
+		}
+
+		// следующая порция начинается после последнего прочитанного заказа
+		filter.After = models.NewOrderCursor(orders[len(orders)-1], filter)
+	}
+}
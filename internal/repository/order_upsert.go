@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+var ErrOrderVersionConflict = errors.New("order was inserted concurrently")
+
+// UpsertOrder сохраняет новый заказ или заменяет сохраненный вместе с delivery, payment и items,
+// если пришедшая версия новее. Устаревшие версии игнорируются: возвращается applied = false без ошибки.
+// checkStatus проверяет смену статуса заказа, если она пришла вместе с новой версией,
+// и начальный статус (from пустой), если заказа еще нет
+func (r *OrderPostgresRepository) UpsertOrder(ctx context.Context, order *models.Order, checkStatus func(from, to models.OrderStatus) error) (bool, error) {
+	tx, err := r.Db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("[UpsertOrder| begin transaction]: , %w", err)
+	}
+	// откат транзакции при ошибке в ней
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// заблокируем строку заказа, чтобы параллельные версии применялись по очереди
+	var stored models.Order
+	query := `SELECT ` + orderColumns + `
+	FROM "order"
+	WHERE order_uid = $1
+	FOR UPDATE`
+
+	err = scanOrder(tx.QueryRow(ctx, query, order.OrderUID), &stored)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// заказа еще нет - обычная вставка; начальный статус проверяет и задает checkStatus
+		err = checkStatus("", order.Status)
+		if err != nil {
+			return false, fmt.Errorf("[UpsertOrder| check initial status]: , %w", err)
+		}
+		err = r.insertFullOrder(ctx, tx, order)
+		if errors.Is(err, ErrOrderAlreadyExistsUUID) {
+			// заказ вставили параллельно - при повторе версии будут сравнены
+			err = ErrOrderVersionConflict
+		}
+		if err != nil {
+			return false, fmt.Errorf("[UpsertOrder| insert order]: , %w", err)
+		}
+
+	case err != nil:
+		return false, fmt.Errorf("[UpsertOrder| select order]: , %w", err)
+
+	case !order.IsNewerThan(&stored):
+		err = tx.Rollback(ctx)
+		if err != nil {
+			return false, fmt.Errorf("[UpsertOrder| rollback transaction]: , %w", err)
+		}
+		return false, nil
+
+	default:
+		err = r.replaceOrder(ctx, tx, order, &stored, checkStatus)
+		if err != nil {
+			return false, fmt.Errorf("[UpsertOrder| replace order]: , %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, fmt.Errorf("[UpsertOrder| commit transaction]: , %w", err)
+	}
+	r.notifyChange(order.OrderUID)
+	return true, nil
+}
+
+// replaceOrder заменяет сохраненный заказ stored новой версией order
+func (r *OrderPostgresRepository) replaceOrder(ctx context.Context, tx pgx.Tx, order, stored *models.Order, checkStatus func(from, to models.OrderStatus) error) error {
+	// статус не пришел - оставляем текущий
+	if order.Status == "" {
+		order.Status = stored.Status
+	}
+	if order.Status != stored.Status {
+		err := checkStatus(stored.Status, order.Status)
+		if err != nil {
+			return fmt.Errorf("[replaceOrder| check status]: , %w", err)
+		}
+	}
+
+	order.Delivery.ID = stored.Delivery.ID
+	order.Payment.ID = stored.Payment.ID
+
+	// items ссылаются на track_number заказа, поэтому удаляем их до изменения заказа
+	_, err := tx.Exec(ctx, `DELETE FROM item WHERE track_number = $1`, stored.TrackNumber)
+	if err != nil {
+		return fmt.Errorf("[replaceOrder| exec delete items]: , %w", err)
+	}
+
+	del := &order.Delivery
+	query := `UPDATE delivery
+	SET name = $1, phone = $2, zip = $3, city = $4, address = $5, region = $6, email = $7
+	WHERE delivery_id = $8`
+
+	_, err = tx.Exec(ctx, query, del.Name, del.Phone, del.Zip, del.City, del.Address, del.Region, del.Email, del.ID)
+	if err != nil {
+		return fmt.Errorf("[replaceOrder| exec update delivery]: , %w", err)
+	}
+
+	p := &order.Payment
+	query = `UPDATE payment
+	SET transaction = $1, request_id = $2, currency = $3, provider = $4, amount = $5, payment_dt = $6, bank = $7, delivery_cost = $8, goods_total = $9, custom_fee = $10
+	WHERE payment_id = $11`
+
+	_, err = tx.Exec(ctx, query, p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount, p.PaymentDt, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee, p.ID)
+	if err != nil {
+		return fmt.Errorf("[replaceOrder| exec update payment]: , %w", err)
+	}
+
+	query = `UPDATE "order"
+	SET track_number = $1, entry = $2, locale = $3, internal_signature = $4, customer_id = $5, delivery_service = $6, shardkey = $7, sm_id = $8, date_created = $9, oof_shard = $10, status = $11, version = $12, updated_at = $13
+	WHERE order_uid = $14`
+
+	_, err = tx.Exec(ctx, query, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Status, order.Version, order.UpdatedAt, order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("[replaceOrder| exec update order]: , %w", uniqueViolation(err))
+	}
+
+	err = r.insertItems(ctx, tx, &order.Items)
+	if err != nil {
+		return fmt.Errorf("[replaceOrder| insert items]: , %w", err)
+	}
+
+	eventType := models.EventOrderUpdated
+	if order.Status != stored.Status {
+		err = r.insertStatusHistory(ctx, tx, &models.StatusChange{
+			OrderUID:   order.OrderUID,
+			FromStatus: stored.Status,
+			ToStatus:   order.Status,
+			Actor:      models.ActorUpsert,
+		})
+		if err != nil {
+			return fmt.Errorf("[replaceOrder| insert status history]: , %w", err)
+		}
+
+		if order.Status == models.StatusCancelled {
+			eventType = models.EventOrderCancelled
+		}
+	}
+
+	err = r.insertOutboxEvent(ctx, tx, eventType, order)
+	if err != nil {
+		return fmt.Errorf("[replaceOrder| insert outbox event]: , %w", err)
+	}
+	return nil
+}
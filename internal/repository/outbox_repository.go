@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+// insertOutboxEvent записывает событие заказа в outbox в той же транзакции, что и сам заказ:
+// если транзакция откатится, событие не будет опубликовано
+func (r *OrderPostgresRepository) insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType string, order *models.Order) error {
+	event, err := newOutboxRow(eventType, order)
+	if err != nil {
+		return fmt.Errorf("[insertOutboxEvent| new event]: , %w", err)
+	}
+
+	query := `INSERT INTO outbox(event_id,event_type,aggregate_id,payload)
+	VALUES ($1,$2,$3,$4)`
+
+	_, err = tx.Exec(ctx, query, event.EventID, event.Type, event.AggregateID, event.Payload)
+	if err != nil {
+		return fmt.Errorf("[insertOutboxEvent| exec insert event]: , %w", err)
+	}
+	return nil
+}
+
+func newOutboxRow(eventType string, order *models.Order) (*models.OutboxEvent, error) {
+	event, err := models.NewOrderEvent(eventType, order)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OutboxEvent{
+		EventID:     event.EventID,
+		Type:        event.Type,
+		AggregateID: event.OrderUID,
+		Payload:     payload,
+	}, nil
+}
+
+// ProcessOutbox блокирует до limit неотправленных событий, передает их в publish и,
+// если публикация прошла успешно, помечает события отправленными.
+// Если пометить не удалось, события будут опубликованы повторно (at-least-once).
+// Возвращает количество обработанных событий
+func (r *OrderPostgresRepository) ProcessOutbox(ctx context.Context, limit int, publish func([]models.OutboxEvent) error) (int, error) {
+	tx, err := r.Db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("[ProcessOutbox| begin transaction]: , %w", err)
+	}
+	// откат транзакции при ошибке в ней
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// SKIP LOCKED позволяет нескольким репликам разбирать outbox, не публикуя одно событие дважды
+	query := `SELECT id,event_id,event_type,aggregate_id,payload,created_at
+	FROM outbox
+	WHERE sent_at IS NULL
+	ORDER BY id
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("[ProcessOutbox| select events]: , %w", err)
+	}
+
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OutboxEvent, error) {
+		var e models.OutboxEvent
+		err := row.Scan(&e.ID, &e.EventID, &e.Type, &e.AggregateID, &e.Payload, &e.CreatedAt)
+		return e, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("[ProcessOutbox| rows scan]: , %w", err)
+	}
+
+	if len(events) == 0 {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("[ProcessOutbox| commit transaction]: , %w", err)
+		}
+		return 0, nil
+	}
+
+	err = publish(events)
+	if err != nil {
+		return 0, fmt.Errorf("[ProcessOutbox| publish events]: , %w", err)
+	}
+
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE outbox SET sent_at = now() WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("[ProcessOutbox| mark events sent]: , %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("[ProcessOutbox| commit transaction]: , %w", err)
+	}
+	return len(events), nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/orders_api/internal/database/postgres"
+)
+
+// ReplicaSet реплики, с которых репозиторий читает заказы
+type ReplicaSet interface {
+	// Reader возвращает пул доступной реплики или nil, если доступных нет
+	Reader() *pgxpool.Pool
+	// MaxStaleness насколько данные на используемой реплике могут отставать от primary
+	MaxStaleness() time.Duration
+	Stats() []postgres.ReplicaStats
+}
+
+type primaryReadKey struct{}
+
+// ReadFromPrimary помечает ctx: чтения с таким контекстом выполняются на primary
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// UseReplicas направляет чтение заказов на реплики, запись по-прежнему идет в primary.
+// Вызывается до начала работы с репозиторием
+func (r *OrderPostgresRepository) UseReplicas(rs ReplicaSet) {
+	r.replicas = rs
+	r.recent = newRecentWrites(rs.MaxStaleness())
+}
+
+// reader возвращает пул для чтения: доступную реплику или primary, если реплик нет или ctx требует свежих данных
+func (r *OrderPostgresRepository) reader(ctx context.Context) *pgxpool.Pool {
+	if !r.readsFromReplica(ctx) {
+		return r.Db
+	}
+	if db := r.replicas.Reader(); db != nil {
+		return db
+	}
+	return r.Db
+}
+
+func (r *OrderPostgresRepository) readsFromReplica(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadKey{}).(bool)
+	return r.replicas != nil && !primary
+}
+
+// freshRead направляет чтение заказа uid на primary, если заказ недавно менялся и реплики могли его еще не получить
+func (r *OrderPostgresRepository) freshRead(ctx context.Context, uid uuid.UUID) context.Context {
+	if r.recent != nil && r.recent.Has(uid) {
+		return ReadFromPrimary(ctx)
+	}
+	return ctx
+}
+
+func (r *OrderPostgresRepository) markWritten(uid uuid.UUID) {
+	if r.recent != nil {
+		r.recent.Add(uid)
+	}
+}
+
+// recentWrites запоминает заказы, измененные за последние window: столько их изменения могут доходить до реплик
+type recentWrites struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries map[uuid.UUID]time.Time
+	sweepAt time.Time
+	now     func() time.Time
+}
+
+func newRecentWrites(window time.Duration) *recentWrites {
+	return &recentWrites{
+		window:  window,
+		entries: make(map[uuid.UUID]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (w *recentWrites) Add(uid uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// истекшие записи убираем не чаще раза в window, чтобы поток записей не копил память
+	now := w.now()
+	if now.After(w.sweepAt) {
+		for k, expiresAt := range w.entries {
+			if now.After(expiresAt) {
+				delete(w.entries, k)
+			}
+		}
+		w.sweepAt = now.Add(w.window)
+	}
+	w.entries[uid] = now.Add(w.window)
+}
+
+func (w *recentWrites) Has(uid uuid.UUID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	expiresAt, ok := w.entries[uid]
+	return ok && !w.now().After(expiresAt)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/orders_api/internal/database/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReplicas struct {
+	pool *pgxpool.Pool // nil - доступных реплик нет
+}
+
+func (f *fakeReplicas) Reader() *pgxpool.Pool          { return f.pool }
+func (f *fakeReplicas) MaxStaleness() time.Duration    { return time.Minute }
+func (f *fakeReplicas) Stats() []postgres.ReplicaStats { return nil }
+
+// newLazyPool пул без соединений: pgxpool подключается только при первом запросе
+func newLazyPool(t *testing.T, host string) *pgxpool.Pool {
+	pool, err := pgxpool.New(context.Background(), "postgresql://user:password@"+host+":5432/orders")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestReader_RoutesReadsToReplicas(t *testing.T) {
+	primary, replica := newLazyPool(t, "primary"), newLazyPool(t, "replica")
+	r := NewOrderPostgresRepository(primary)
+	ctx := context.Background()
+
+	// без реплик все читается с primary
+	assert.Same(t, primary, r.reader(ctx))
+
+	replicas := &fakeReplicas{pool: replica}
+	r.UseReplicas(replicas)
+	assert.Same(t, replica, r.reader(ctx))
+	assert.Same(t, primary, r.reader(ReadFromPrimary(ctx)))
+
+	// недавно измененный заказ читается с primary
+	uid := uuid.Must(uuid.NewV4())
+	assert.Same(t, replica, r.reader(r.freshRead(ctx, uid)))
+	r.notifyChange(uid)
+	assert.Same(t, primary, r.reader(r.freshRead(ctx, uid)))
+
+	// недоступные реплики - чтение с primary
+	replicas.pool = nil
+	assert.Same(t, primary, r.reader(ctx))
+}
+
+func TestRecentWrites_Expire(t *testing.T) {
+	w := newRecentWrites(time.Minute)
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	uid := uuid.Must(uuid.NewV4())
+	w.Add(uid)
+	assert.True(t, w.Has(uid))
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, w.Has(uid))
+
+	// истекшая запись удаляется при следующей записи
+	w.Add(uuid.Must(uuid.NewV4()))
+	assert.NotContains(t, w.entries, uid)
+}
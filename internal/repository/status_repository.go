@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/orders_api/internal/models"
+)
+
+var ErrOrderStatusConflict = errors.New("order status was changed concurrently")
+
+func (r *OrderPostgresRepository) GetOrderStatus(ctx context.Context, uid uuid.UUID) (models.OrderStatus, error) {
+	var status models.OrderStatus
+
+	query := `SELECT status FROM "order" WHERE order_uid = $1`
+
+	err := r.Db.QueryRow(ctx, query, uid).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("[GetOrderStatus|row scan]: , %w", ErrOrderNotFoundByUUID)
+		}
+		return "", fmt.Errorf("[GetOrderStatus|row scan]: , %w", err)
+	}
+	return status, nil
+}
+
+// UpdateOrderStatus переводит заказ из change.FromStatus в change.ToStatus, пишет запись в историю
+// и событие order.status_changed (order.cancelled при отмене) в outbox одной транзакцией.
+// Если статус успел измениться с момента проверки перехода, возвращает ErrOrderStatusConflict
+func (r *OrderPostgresRepository) UpdateOrderStatus(ctx context.Context, change *models.StatusChange) error {
+	tx, err := r.Db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| begin transaction]: , %w", err)
+	}
+	// откат транзакции при ошибке в ней
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	query := `UPDATE "order" SET status = $1 WHERE order_uid = $2 AND status = $3`
+
+	tag, err := tx.Exec(ctx, query, change.ToStatus, change.OrderUID, change.FromStatus)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| exec update status]: , %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = ErrOrderStatusConflict
+		return fmt.Errorf("[UpdateOrderStatus| exec update status]: , %w", err)
+	}
+
+	err = r.insertStatusHistory(ctx, tx, change)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| insert status history]: , %w", err)
+	}
+
+	// событие несет итоги заказа, поэтому заказ читается в той же транзакции уже с новым статусом
+	rows, err := tx.Query(ctx, selectOrdersQuery+`WHERE o.order_uid = $1`, change.OrderUID)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| select order]: , %w", err)
+	}
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| scan order]: , %w", err)
+	}
+	err = loadItems(ctx, tx, orders)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| load items]: , %w", err)
+	}
+
+	eventType := models.EventOrderStatusChanged
+	if change.ToStatus == models.StatusCancelled {
+		eventType = models.EventOrderCancelled
+	}
+	err = r.insertOutboxEvent(ctx, tx, eventType, orders[0])
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| insert outbox event]: , %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("[UpdateOrderStatus| commit transaction]: , %w", err)
+	}
+	r.notifyChange(change.OrderUID)
+	return nil
+}
+
+func (r *OrderPostgresRepository) GetStatusHistory(ctx context.Context, uid uuid.UUID) ([]models.StatusChange, error) {
+	query := `SELECT order_uid,COALESCE(from_status, ''),to_status,actor,changed_at
+	FROM order_status_history
+	WHERE order_uid = $1
+	ORDER BY id`
+
+	rows, err := r.reader(r.freshRead(ctx, uid)).Query(ctx, query, uid)
+	if err != nil {
+		return nil, fmt.Errorf("[GetStatusHistory|rows scan]: , %w", err)
+	}
+
+	history, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.StatusChange, error) {
+		var ch models.StatusChange
+		err := row.Scan(&ch.OrderUID, &ch.FromStatus, &ch.ToStatus, &ch.Actor, &ch.ChangedAt)
+		return ch, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[GetStatusHistory|rows scan change]: , %w", err)
+	}
+
+	if len(history) == 0 {
+		return nil, fmt.Errorf("[GetStatusHistory|rows scan]: , %w", ErrOrderNotFoundByUUID)
+	}
+	return history, nil
+}
+
+// insertStatusHistory пишет запись истории статусов и проставляет в change время изменения
+func (r *OrderPostgresRepository) insertStatusHistory(ctx context.Context, tx pgx.Tx, change *models.StatusChange) error {
+	query := `INSERT INTO order_status_history(order_uid,from_status,to_status,actor)
+	VALUES ($1,NULLIF($2, ''),$3,$4)
+	RETURNING changed_at`
+
+	err := tx.QueryRow(ctx, query, change.OrderUID, change.FromStatus, change.ToStatus, change.Actor).Scan(&change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("[insertStatusHistory| exec insert history]: , %w", err)
+	}
+	return nil
+}
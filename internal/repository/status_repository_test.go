@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outboxEvents события заказа uid в outbox в порядке записи
+func outboxEvents(t *testing.T, r *OrderPostgresRepository, uid uuid.UUID) []models.OrderEvent {
+	t.Helper()
+	rows, err := r.Db.Query(context.Background(), `SELECT payload FROM outbox WHERE aggregate_id = $1 ORDER BY id`, uid)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var events []models.OrderEvent
+	for rows.Next() {
+		var payload []byte
+		require.NoError(t, rows.Scan(&payload))
+		var event models.OrderEvent
+		require.NoError(t, json.Unmarshal(payload, &event))
+		events = append(events, event)
+	}
+	require.NoError(t, rows.Err())
+	return events
+}
+
+func TestUpdateOrderStatus_OutboxEvent(t *testing.T) {
+	r := NewOrderPostgresRepository(newDBPool(t, nil))
+	ctx := context.Background()
+
+	orders := newBatchOrders(t, r, 1)
+	order := orders[0]
+	_, err := r.InsertOrder(ctx, order)
+	require.NoError(t, err)
+
+	for _, to := range []models.OrderStatus{models.StatusPaid, models.StatusCancelled} {
+		from, err := r.GetOrderStatus(ctx, order.OrderUID)
+		require.NoError(t, err)
+		require.NoError(t, r.UpdateOrderStatus(ctx, &models.StatusChange{
+			OrderUID:   order.OrderUID,
+			FromStatus: from,
+			ToStatus:   to,
+			Actor:      "test",
+		}))
+	}
+
+	// конфликт не пишет ни статус, ни событие
+	err = r.UpdateOrderStatus(ctx, &models.StatusChange{OrderUID: order.OrderUID, FromStatus: models.StatusPaid, ToStatus: models.StatusAssembling, Actor: "test"})
+	assert.ErrorIs(t, err, ErrOrderStatusConflict)
+
+	events := outboxEvents(t, r, order.OrderUID)
+	require.Len(t, events, 3)
+	assert.Equal(t, models.EventOrderCreated, events[0].Type)
+	assert.Equal(t, models.EventOrderStatusChanged, events[1].Type)
+	assert.Equal(t, models.StatusPaid, events[1].Status)
+	assert.Equal(t, models.EventOrderCancelled, events[2].Type)
+	assert.Equal(t, models.StatusCancelled, events[2].Status)
+	assert.Equal(t, order.Payment.Amount, events[2].Totals.Amount)
+	assert.Equal(t, len(order.Items), events[2].Totals.ItemsCount)
+}
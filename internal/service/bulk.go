@@ -0,0 +1,193 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/orders_api/internal/ingest"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/utils"
+)
+
+// importItem заказ из тела импорта вместе с номером его строки
+type importItem struct {
+	line  int
+	order *models.Order
+}
+
+// ImportOrders сохраняет заказы из dec пачками по batchSize, workers пачек записываются параллельно.
+// Ошибки отдельных заказов попадают в отчет, а импорт продолжается. Возвращает отчет по прочитанным строкам
+// и ошибку, если поток пришлось прервать (отчет при этом содержит все строки до нее).
+// В отчете остаются первые maxLines строк, остальные учитываются только в итогах; 0 - без ограничения
+func (s *serviceOrder) ImportOrders(ctx context.Context, dec ingest.Decoder, batchSize, workers, maxLines int) (*models.ImportReport, error) {
+	batchSize, workers = max(batchSize, 1), max(workers, 1)
+
+	batches := make(chan []importItem, workers)
+	results := make(chan []models.ImportLine, workers)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				results <- s.importBatch(ctx, batch)
+			}
+		}()
+	}
+
+	// читаем поток параллельно с записью; отчет собирается в этой горутине
+	var readErr error
+	go func() {
+		readErr = readImport(dec, batchSize, batches, results)
+		close(batches)
+		wg.Wait()
+		close(results)
+	}()
+
+	report := &models.ImportReport{}
+	kept := &reportLines{max: maxLines}
+	for lines := range results {
+		for _, line := range lines {
+			report.Count(line.Status)
+			kept.add(line)
+		}
+	}
+	report.Lines, report.LinesOmitted = kept.sorted(), kept.omitted
+
+	if readErr != nil {
+		report.Error = readErr.Error()
+		return report, fmt.Errorf("[ImportOrders| read]: %w", readErr)
+	}
+	return report, nil
+}
+
+// readImport разбирает и проверяет заказы и отправляет валидные пачками в batches, невалидные - сразу в results.
+// Заказ с version или updated_at невалиден, как и в POST /orders: импорт только создает заказы
+func readImport(dec ingest.Decoder, batchSize int, batches chan<- []importItem, results chan<- []models.ImportLine) error {
+	batch := make([]importItem, 0, batchSize)
+	for {
+		line, order, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		var lineErr *ingest.LineError
+		if errors.As(err, &lineErr) {
+			results <- []models.ImportLine{{Line: lineErr.Line, Status: models.ImportInvalid, Errors: []string{lineErr.Err.Error()}}}
+			continue
+		}
+		if err != nil {
+			// уже проверенные заказы сохраняем, чтобы отчет совпадал с тем, что записано в БД
+			if len(batch) > 0 {
+				batches <- batch
+			}
+			return err
+		}
+
+		err = checkNewOrder(order)
+		if err != nil {
+			results <- []models.ImportLine{{
+				Line:     line,
+				OrderUID: order.OrderUID.String(),
+				Status:   models.ImportInvalid,
+				Errors:   []string{ErrValidateJSON.Error()},
+				Fields:   utils.FieldErrors(err),
+			}}
+			continue
+		}
+
+		batch = append(batch, importItem{line: line, order: order})
+		if len(batch) == batchSize {
+			batches <- batch
+			batch = make([]importItem, 0, batchSize)
+		}
+	}
+
+	if len(batch) > 0 {
+		batches <- batch
+	}
+	return nil
+}
+
+// reportLines строки отчета с наименьшими номерами, не больше max (0 - без ограничения).
+// Хранятся в куче, в корне которой строка с наибольшим номером: ее вытесняет строка с меньшим
+type reportLines struct {
+	lines   []models.ImportLine
+	max     int
+	omitted int
+}
+
+func (h *reportLines) Len() int           { return len(h.lines) }
+func (h *reportLines) Less(i, j int) bool { return h.lines[i].Line > h.lines[j].Line }
+func (h *reportLines) Swap(i, j int)      { h.lines[i], h.lines[j] = h.lines[j], h.lines[i] }
+func (h *reportLines) Push(x any)         { h.lines = append(h.lines, x.(models.ImportLine)) }
+func (h *reportLines) Pop() any {
+	last := h.lines[len(h.lines)-1]
+	h.lines = h.lines[:len(h.lines)-1]
+	return last
+}
+
+// add добавляет строку в отчет; сверх max в отчете остаются строки с меньшими номерами
+func (h *reportLines) add(line models.ImportLine) {
+	if h.max <= 0 || len(h.lines) < h.max {
+		heap.Push(h, line)
+		return
+	}
+
+	h.omitted++
+	if line.Line < h.lines[0].Line {
+		h.lines[0] = line
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted строки отчета по возрастанию номера
+func (h *reportLines) sorted() []models.ImportLine {
+	lines := append([]models.ImportLine{}, h.lines...)
+	slices.SortFunc(lines, func(a, b models.ImportLine) int {
+		return a.Line - b.Line
+	})
+	return lines
+}
+
+// importBatch сохраняет пачку проверенных в readImport заказов одной транзакцией и сопоставляет ошибки с результатами строк
+func (s *serviceOrder) importBatch(ctx context.Context, batch []importItem) []models.ImportLine {
+	orders := make([]*models.Order, len(batch))
+	for i, item := range batch {
+		orders[i] = item.order
+	}
+
+	orderErrs, err := s.insertOrders(ctx, orders)
+	lines := make([]models.ImportLine, len(batch))
+	for i, item := range batch {
+		lines[i] = models.ImportLine{Line: item.line, OrderUID: item.order.OrderUID.String()}
+		switch {
+		case err != nil:
+			lines[i].Status = models.ImportFailed
+			lines[i].Errors = []string{"failed to save batch"}
+		default:
+			lines[i].Status, lines[i].Errors = importStatus(orderErrs[i])
+		}
+	}
+	return lines
+}
+
+// importStatus результат строки по ошибке сохранения проверенного заказа
+func importStatus(err error) (models.ImportStatus, []string) {
+	switch {
+	case err == nil:
+		return models.ImportAccepted, nil
+	case errors.Is(err, repository.ErrOrderAlreadyExistsUUID):
+		return models.ImportDuplicate, []string{"order_uid already exists"}
+	case errors.Is(err, repository.ErrOrderAlreadyExistsTrack):
+		return models.ImportDuplicate, []string{"track_number already exists"}
+	default:
+		return models.ImportFailed, []string{"failed to save order"}
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/ingest"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportOrders(t *testing.T) {
+	s, repo := newTestService(t, 0)
+
+	accepted, duplicate, invalid, last := testOrder(), testOrder(), testOrder(), testOrder()
+	invalid.Items = append(invalid.Items, invalid.Items[0])
+	invalid.Items[1].Sale = 150
+
+	var body strings.Builder
+	for i, order := range []*models.Order{accepted, nil, invalid, duplicate, last} {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		if order == nil {
+			body.WriteString(`{"order_uid": 1}`)
+			continue
+		}
+		data, err := json.Marshal(order)
+		require.NoError(t, err)
+		body.Write(data)
+	}
+
+	insertErrs := map[uuid.UUID]error{duplicate.OrderUID: repository.ErrOrderAlreadyExistsTrack}
+	repo.EXPECT().InsertOrders(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, orders []*models.Order) ([]error, error) {
+			errs := make([]error, len(orders))
+			for i, order := range orders {
+				errs[i] = insertErrs[order.OrderUID]
+			}
+			return errs, nil
+		}).Times(2)
+
+	dec, err := ingest.NewDecoder(strings.NewReader(body.String()))
+	require.NoError(t, err)
+	report, err := s.ImportOrders(context.Background(), dec, 2, 2, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 2, report.Accepted)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, 2, report.Invalid)
+	require.Len(t, report.Lines, 5)
+
+	assert.Equal(t, models.ImportLine{Line: 1, OrderUID: accepted.OrderUID.String(), Status: models.ImportAccepted}, report.Lines[0])
+	assert.Equal(t, models.ImportInvalid, report.Lines[1].Status)
+	assert.Equal(t, models.ImportLine{
+		Line:     3,
+		OrderUID: invalid.OrderUID.String(),
+		Status:   models.ImportInvalid,
+		Errors:   []string{ErrValidateJSON.Error()},
+		Fields:   []models.FieldError{{Field: "items[1].sale", Rule: "lte", Param: "100", Message: "must be less than or equal to 100"}},
+	}, report.Lines[2])
+	assert.Equal(t, models.ImportLine{
+		Line:     4,
+		OrderUID: duplicate.OrderUID.String(),
+		Status:   models.ImportDuplicate,
+		Errors:   []string{"track_number already exists"},
+	}, report.Lines[3])
+	assert.Equal(t, models.ImportAccepted, report.Lines[4].Status)
+
+	// сохраненные заказы сразу доступны из кэша
+	_, ok := s.Cache.Get(last.OrderUID)
+	assert.True(t, ok)
+}
+
+// brokenDecoder отдает заказы orders, после чего поток обрывается ошибкой err
+type brokenDecoder struct {
+	orders []*models.Order
+	line   int
+	err    error
+}
+
+func (d *brokenDecoder) Next() (int, *models.Order, error) {
+	if d.line == len(d.orders) {
+		return 0, nil, d.err
+	}
+	d.line++
+	return d.line, d.orders[d.line-1], nil
+}
+
+func TestImportOrders_SavesBatchReadBeforeError(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	orders := []*models.Order{testOrder(), testOrder(), testOrder()}
+	readErr := errors.New("connection reset")
+
+	// пачки по 2: вторая пачка не заполнена, когда поток обрывается
+	var saved int
+	repo.EXPECT().InsertOrders(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, orders []*models.Order) ([]error, error) {
+			saved += len(orders)
+			return make([]error, len(orders)), nil
+		}).Times(2)
+
+	report, err := s.ImportOrders(context.Background(), &brokenDecoder{orders: orders, err: readErr}, 2, 1, 0)
+	assert.ErrorIs(t, err, readErr)
+	assert.Equal(t, 3, saved)
+	assert.Equal(t, 3, report.Accepted)
+	require.Len(t, report.Lines, 3)
+	assert.Equal(t, readErr.Error(), report.Error)
+}
+
+func TestImportOrders_ReportMaxLines(t *testing.T) {
+	s, repo := newTestService(t, 0)
+
+	var body strings.Builder
+	for i := range 10 {
+		fmt.Fprintf(&body, `{"order_uid": %d}`+"\n", i)
+	}
+	repo.EXPECT().InsertOrders(gomock.Any(), gomock.Any()).Times(0)
+
+	dec, err := ingest.NewDecoder(strings.NewReader(body.String()))
+	require.NoError(t, err)
+	report, err := s.ImportOrders(context.Background(), dec, 2, 2, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, report.Total)
+	assert.Equal(t, 10, report.Invalid)
+	assert.Equal(t, 7, report.LinesOmitted)
+	require.Len(t, report.Lines, 3)
+	for i, line := range report.Lines {
+		assert.Equal(t, i+1, line.Line)
+	}
+}
+
+func TestImportOrders_RejectsVersionedOrders(t *testing.T) {
+	s, repo := newTestService(t, 0)
+
+	order, versioned := testOrder(), testOrder()
+	versioned.Version = 3
+	var body strings.Builder
+	for _, o := range []*models.Order{versioned, order} {
+		data, err := json.Marshal(o)
+		require.NoError(t, err)
+		body.Write(data)
+		body.WriteString("\n")
+	}
+
+	// импорт только создает заказы: заказ с версией не заменяет сохраненный и не попадает в пачку
+	repo.EXPECT().InsertOrders(gomock.Any(), gomock.Len(1)).
+		DoAndReturn(func(_ context.Context, orders []*models.Order) ([]error, error) {
+			assert.Equal(t, order.OrderUID, orders[0].OrderUID)
+			return []error{nil}, nil
+		})
+
+	dec, err := ingest.NewDecoder(strings.NewReader(body.String()))
+	require.NoError(t, err)
+	report, err := s.ImportOrders(context.Background(), dec, 2, 1, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Accepted)
+	assert.Equal(t, 1, report.Invalid)
+	require.Len(t, report.Lines, 2)
+	assert.Equal(t, models.ImportLine{
+		Line:     1,
+		OrderUID: versioned.OrderUID.String(),
+		Status:   models.ImportInvalid,
+		Errors:   []string{ErrValidateJSON.Error()},
+		Fields:   []models.FieldError{{Field: "version", Rule: "excluded", Message: "must not be set when creating an order"}},
+	}, report.Lines[0])
+}
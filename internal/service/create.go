@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/tracing"
+	"github.com/orders_api/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different order")
+
+// CreateOrder сохраняет заказ, пришедший по HTTP, тем же путем, что и заказы из Kafka.
+// Повтор запроса с тем же idempotencyKey возвращает уже созданный заказ и replayed = true.
+// Пустой idempotencyKey - без идемпотентности. Заказ с версией отклоняется: он заменил бы сохраненный заказ
+func (s *serviceOrder) CreateOrder(ctx context.Context, order *models.Order, idempotencyKey string) (_ *models.Order, _ bool, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "serviceOrder.CreateOrder", trace.WithAttributes(attribute.String("order.uid", order.OrderUID.String())))
+	defer func() { tracing.End(span, err) }()
+
+	// отпечаток снимаем до проверки и сохранения: они дополняют заказ
+	var hash string
+	if idempotencyKey != "" {
+		hash, err = requestHash(order)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	err = checkNewOrder(order)
+	if err != nil {
+		return nil, false, fmt.Errorf("[CreateOrder|check order]: %w", err)
+	}
+
+	if idempotencyKey == "" {
+		newOrder, err := s.insertOrder(ctx, order, nil)
+		return newOrder, false, err
+	}
+
+	stored, err := s.replayOrder(ctx, idempotencyKey, hash)
+	if stored != nil || err != nil {
+		return stored, err == nil, err
+	}
+
+	// ключ сохраняется в одной транзакции с заказом: созданный заказ всегда находится по ключу
+	newOrder, err := s.insertOrder(ctx, order, &models.IdempotencyKey{
+		Key:         idempotencyKey,
+		RequestHash: hash,
+		OrderUID:    order.OrderUID,
+	})
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) || errors.Is(err, repository.ErrOrderAlreadyExistsUUID) {
+		// заказ мог успеть создать параллельный запрос с тем же ключом
+		stored, replayErr := s.replayOrder(ctx, idempotencyKey, hash)
+		if stored != nil || replayErr != nil {
+			return stored, replayErr == nil, replayErr
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return newOrder, false, nil
+}
+
+// versionFieldsError ошибка проверки полей версии, которые нельзя передавать при создании заказа
+func versionFieldsError(order *models.Order) *utils.ValidationError {
+	var fields []models.FieldError
+	if order.Version > 0 {
+		fields = append(fields, models.FieldError{Field: "version", Rule: "excluded", Message: "must not be set when creating an order"})
+	}
+	if order.UpdatedAt != nil {
+		fields = append(fields, models.FieldError{Field: "updated_at", Rule: "excluded", Message: "must not be set when creating an order"})
+	}
+	return &utils.ValidationError{Fields: fields}
+}
+
+// replayOrder возвращает заказ, уже созданный по ключу, или nil, если ключ не использовался
+func (s *serviceOrder) replayOrder(ctx context.Context, key, hash string) (*models.Order, error) {
+	stored, err := s.Repo.GetIdempotencyKey(ctx, key)
+	if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[CreateOrder| get idempotency key]: %w", err)
+	}
+	if stored.RequestHash != hash {
+		return nil, fmt.Errorf("[CreateOrder| key %q]: %w", key, ErrIdempotencyKeyReused)
+	}
+
+	order, err := s.GetOrderByUID(ctx, stored.OrderUID.String())
+	if err != nil {
+		return nil, fmt.Errorf("[CreateOrder| get created order]: %w", err)
+	}
+	return order, nil
+}
+
+// requestHash отпечаток заказа, по которому повтор запроса отличается от другого заказа с тем же ключом
+func requestHash(order *models.Order) (string, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return "", fmt.Errorf("[requestHash| marshal order]: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// testOrder валидный заказ для тестов сохранения
+func testOrder() *models.Order {
+	uid := uuid.Must(uuid.NewV4())
+	return &models.Order{
+		OrderUID:    uid,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: models.Delivery{
+			Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+			Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction: uid, Currency: "USD", Provider: "wbpay", Amount: 1817,
+			PaymentDt: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+		},
+		Items: []models.Item{{
+			ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Rid: "ab4219087a764ae0btest",
+			Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+	}
+}
+
+func TestCreateOrder_SavesIdempotencyKey(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	order := testOrder()
+	hash, err := requestHash(order)
+	require.NoError(t, err)
+
+	repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").Return(nil, repository.ErrIdempotencyKeyNotFound)
+	repo.EXPECT().InsertOrderWithKey(gomock.Any(), order, &models.IdempotencyKey{Key: "key-1", RequestHash: hash, OrderUID: order.OrderUID}).
+		Return(order, nil)
+
+	created, replayed, err := s.CreateOrder(context.Background(), order, "key-1")
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, models.StatusCreated, created.Status)
+}
+
+func TestCreateOrder_ReplaysSameRequest(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	order := testOrder()
+	hash, err := requestHash(order)
+	require.NoError(t, err)
+
+	repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").
+		Return(&models.IdempotencyKey{Key: "key-1", RequestHash: hash, OrderUID: order.OrderUID}, nil)
+	repo.EXPECT().GetOrderByUID(gomock.Any(), order.OrderUID).Return(order, nil)
+
+	stored, replayed, err := s.CreateOrder(context.Background(), order, "key-1")
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, order.OrderUID, stored.OrderUID)
+}
+
+func TestCreateOrder_KeyReusedWithAnotherOrder(t *testing.T) {
+	s, repo := newTestService(t, 0)
+
+	repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").
+		Return(&models.IdempotencyKey{Key: "key-1", RequestHash: "other", OrderUID: uuid.Must(uuid.NewV4())}, nil)
+
+	_, _, err := s.CreateOrder(context.Background(), testOrder(), "key-1")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestCreateOrder_ConcurrentRequestWithSameKey(t *testing.T) {
+	tests := []struct {
+		Name        string
+		InsertError error
+	}{
+		// параллельный запрос с тем же заказом вставил его первым
+		{Name: "Same_order", InsertError: repository.ErrOrderAlreadyExistsUUID},
+		// параллельный запрос первым сохранил ключ: транзакция с заказом откатывается
+		{Name: "Same_key", InsertError: repository.ErrIdempotencyKeyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			s, repo := newTestService(t, 0)
+			order := testOrder()
+			hash, err := requestHash(order)
+			require.NoError(t, err)
+
+			gomock.InOrder(
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").Return(nil, repository.ErrIdempotencyKeyNotFound),
+				repo.EXPECT().InsertOrderWithKey(gomock.Any(), order, gomock.Any()).Return(nil, tt.InsertError),
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").
+					Return(&models.IdempotencyKey{Key: "key-1", RequestHash: hash, OrderUID: order.OrderUID}, nil),
+			)
+			repo.EXPECT().GetOrderByUID(gomock.Any(), order.OrderUID).Return(order, nil)
+
+			_, replayed, err := s.CreateOrder(context.Background(), order, "key-1")
+			require.NoError(t, err)
+			assert.True(t, replayed)
+		})
+	}
+}
+
+func TestCreateOrder_KeyTakenByAnotherOrder(t *testing.T) {
+	s, repo := newTestService(t, 0)
+
+	// параллельный запрос первым сохранил тот же ключ с другим заказом
+	gomock.InOrder(
+		repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").Return(nil, repository.ErrIdempotencyKeyNotFound),
+		repo.EXPECT().InsertOrderWithKey(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, repository.ErrIdempotencyKeyExists),
+		repo.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").
+			Return(&models.IdempotencyKey{Key: "key-1", RequestHash: "other", OrderUID: uuid.Must(uuid.NewV4())}, nil),
+	)
+
+	_, _, err := s.CreateOrder(context.Background(), testOrder(), "key-1")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestCreateOrder_WithoutKey(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	order := testOrder()
+
+	repo.EXPECT().InsertOrder(gomock.Any(), order).Return(nil, repository.ErrOrderAlreadyExistsTrack)
+
+	_, _, err := s.CreateOrder(context.Background(), order, "")
+	assert.ErrorIs(t, err, repository.ErrOrderAlreadyExistsTrack)
+}
+
+func TestCreateOrder_RejectsVersionedOrder(t *testing.T) {
+	s, _ := newTestService(t, 0)
+	order := testOrder()
+	order.Version = 2
+
+	_, _, err := s.CreateOrder(context.Background(), order, "key-1")
+	assert.ErrorIs(t, err, ErrValidateJSON)
+	assert.Equal(t, []models.FieldError{{Field: "version", Rule: "excluded", Message: "must not be set when creating an order"}}, utils.FieldErrors(err))
+}
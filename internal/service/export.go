@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OrderExport выгрузка заказов: передает подходящие заказы в fn порциями по мере чтения из БД
+type OrderExport func(ctx context.Context, fn func(orders []*models.Order) error) error
+
+// ExportOrders проверяет фильтры выгрузки и возвращает выгрузку, которая читает заказы порциями по batchSize.
+// Ошибки параметров возвращаются сразу, до начала ответа клиенту. Размер страницы и курсор списка в выгрузке не учитываются
+func (s *serviceOrder) ExportOrders(query *models.OrderListQuery, batchSize int) (OrderExport, error) {
+	exportQuery := *query
+	exportQuery.Limit = 0
+	exportQuery.Cursor = ""
+
+	filter, err := newOrderFilter(&exportQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, fn func(orders []*models.Order) error) (err error) {
+		ctx, span := tracing.Tracer().Start(ctx, "serviceOrder.ExportOrders")
+		defer func() { tracing.End(span, err) }()
+
+		var exported int
+		err = s.Repo.ExportOrders(ctx, filter, batchSize, func(orders []*models.Order) error {
+			exported += len(orders)
+			return fn(orders)
+		})
+		span.SetAttributes(attribute.Int("orders.count", exported))
+		return err
+	}, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/utils"
+)
+
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+const defaultPageLimit = 20
+
+func (s *serviceOrder) ListOrders(ctx context.Context, query *models.OrderListQuery) (*models.OrderPage, error) {
+	filter, err := newOrderFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// запросим на один заказ больше, чтобы узнать, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+
+	orders, err := s.Repo.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return newOrderPage(orders, filter, limit)
+}
+
+// newOrderPage страница из первых limit заказов; курсор следующей страницы есть, только если заказов больше limit
+func newOrderPage(orders []*models.Order, filter *models.OrderFilter, limit int) (*models.OrderPage, error) {
+	page := &models.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		cursor, err := models.NewOrderCursor(page.Orders[limit-1], filter).Encode()
+		if err != nil {
+			return nil, fmt.Errorf("[newOrderPage|encode cursor]: %w", err)
+		}
+		page.NextCursor = cursor
+	}
+	return page, nil
+}
+
+// newOrderFilter проверяет параметры запроса и переводит их в фильтр репозитория
+func newOrderFilter(query *models.OrderListQuery) (*models.OrderFilter, error) {
+	err := utils.VaildateStructs(query)
+	if err != nil {
+		return nil, fmt.Errorf("[ListOrders|validate query]: %w: %w", ErrValidateJSON, err)
+	}
+
+	filter := &models.OrderFilter{
+		CustomerID:      query.CustomerID,
+		DeliveryService: query.DeliveryService,
+		Entry:           query.Entry,
+		Locale:          query.Locale,
+		Currency:        query.Currency,
+		Provider:        query.Provider,
+		Bank:            query.Bank,
+		AmountMin:       query.AmountMin,
+		AmountMax:       query.AmountMax,
+		Sort:            query.Sort,
+		Desc:            query.Order != models.SortAsc,
+		Limit:           query.Limit,
+	}
+	if filter.Sort == "" {
+		filter.Sort = models.SortByDateCreated
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+
+	// формат дат уже проверен валидатором
+	if query.DateFrom != "" {
+		from, _ := time.Parse(time.RFC3339, query.DateFrom)
+		filter.DateFrom = &from
+	}
+	if query.DateTo != "" {
+		to, _ := time.Parse(time.RFC3339, query.DateTo)
+		filter.DateTo = &to
+	}
+
+	if query.Cursor != "" {
+		cursor, err := models.DecodeOrderCursor(query.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("[ListOrders|decode cursor]: %w", ErrInvalidCursor)
+		}
+		// курсор от другой сортировки указывает на чужую позицию в списке
+		if cursor.Sort != filter.Sort || cursor.Desc != filter.Desc {
+			return nil, fmt.Errorf("[ListOrders|cursor sort %s]: %w", cursor.Sort, ErrInvalidCursor)
+		}
+		filter.After = cursor
+	}
+	return filter, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewOrderFilter(t *testing.T) {
+	filter, err := newOrderFilter(&models.OrderListQuery{DateFrom: "2021-11-26T06:22:19Z"})
+	require.NoError(t, err)
+	assert.Equal(t, models.SortByDateCreated, filter.Sort)
+	assert.True(t, filter.Desc)
+	assert.Equal(t, defaultPageLimit, filter.Limit)
+	assert.Equal(t, time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), *filter.DateFrom)
+
+	_, err = newOrderFilter(&models.OrderListQuery{Limit: 1000})
+	assert.ErrorIs(t, err, ErrValidateJSON)
+
+	_, err = newOrderFilter(&models.OrderListQuery{DateTo: "26.11.2021"})
+	assert.ErrorIs(t, err, ErrValidateJSON)
+
+	_, err = newOrderFilter(&models.OrderListQuery{Cursor: "%%%"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNewOrderFilter_Cursor(t *testing.T) {
+	order := &models.Order{
+		OrderUID:    uuid.Must(uuid.NewV4()),
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Payment:     models.Payment{Amount: 1817},
+	}
+	cursor, err := models.NewOrderCursor(order, &models.OrderFilter{Sort: models.SortByAmount}).Encode()
+	require.NoError(t, err)
+
+	filter, err := newOrderFilter(&models.OrderListQuery{Cursor: cursor, Sort: models.SortByAmount, Order: models.SortAsc})
+	require.NoError(t, err)
+	assert.Equal(t, order.OrderUID, filter.After.OrderUID)
+	assert.Equal(t, 1817, filter.After.Amount)
+
+	// курсор страницы с другой сортировкой не принимается
+	_, err = newOrderFilter(&models.OrderListQuery{Cursor: cursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestExportOrders_IgnoresPaging(t *testing.T) {
+	s, repo := newTestService(t, 0)
+
+	// размер страницы и курсор списка к выгрузке не относятся: limit больше максимума страницы не ошибка
+	run, err := s.ExportOrders(&models.OrderListQuery{Limit: 1000, Cursor: "%%%", Currency: "USD"}, 100)
+	require.NoError(t, err)
+
+	orders := []*models.Order{testOrder()}
+	repo.EXPECT().ExportOrders(gomock.Any(), gomock.Any(), 100, gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter *models.OrderFilter, _ int, fn func([]*models.Order) error) error {
+			assert.Equal(t, "USD", filter.Currency)
+			assert.Nil(t, filter.After)
+			return fn(orders)
+		})
+
+	var exported []*models.Order
+	err = run(context.Background(), func(batch []*models.Order) error {
+		exported = append(exported, batch...)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, orders, exported)
+
+	_, err = s.ExportOrders(&models.OrderListQuery{DateTo: "26.11.2021"}, 100)
+	assert.ErrorIs(t, err, ErrValidateJSON)
+}
+
+func TestGetCustomerOrders_Pages(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	orders := make([]*models.Order, 3)
+	for i := range orders {
+		orders[i] = &models.Order{
+			OrderUID:    uuid.Must(uuid.NewV4()),
+			CustomerID:  "test",
+			TrackNumber: uuid.Must(uuid.NewV4()).String(),
+			DateCreated: now.Add(-time.Duration(i) * time.Hour),
+		}
+	}
+
+	// страница с продолжением не кэшируется как список покупателя
+	repo.EXPECT().GetOrdersByCustomer(gomock.Any(), "test", 3, nil).Return(orders, nil)
+	page, err := s.GetCustomerOrders(ctx, "test", &models.CustomerOrdersQuery{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, orders[:2], page.Orders)
+	require.NotEmpty(t, page.NextCursor)
+
+	cursor, err := models.DecodeOrderCursor(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, orders[1].OrderUID, cursor.OrderUID)
+
+	// первая страница без продолжения - полный список, следующие страницы отдаются из кэша
+	repo.EXPECT().GetOrdersByCustomer(gomock.Any(), "test", 21, nil).Return(orders, nil)
+	page, err = s.GetCustomerOrders(ctx, "test", &models.CustomerOrdersQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, orders, page.Orders)
+	assert.Empty(t, page.NextCursor)
+
+	first, err := s.GetCustomerOrders(ctx, "test", &models.CustomerOrdersQuery{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, orders[:2], first.Orders)
+
+	next, err := s.GetCustomerOrders(ctx, "test", &models.CustomerOrdersQuery{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, orders[2:], next.Orders)
+	assert.Empty(t, next.NextCursor)
+
+	_, err = s.GetCustomerOrders(ctx, "test", &models.CustomerOrdersQuery{Limit: 1000})
+	assert.ErrorIs(t, err, ErrValidateJSON)
+}
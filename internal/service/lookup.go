@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/utils"
+)
+
+func (s *serviceOrder) GetOrderByTrack(ctx context.Context, track string) (*models.Order, error) {
+
+	// сначала обращаемся к кэшу
+	respOrder, exist := s.Cache.GetByTrack(track)
+	if exist {
+		slog.Info("got order from cache", "track_number", track)
+		return respOrder, nil
+	}
+
+	respOrder, err := s.Repo.GetOrderByTrack(ctx, track)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheOrder(respOrder)
+	return respOrder, nil
+}
+
+func (s *serviceOrder) GetOrderByTransaction(ctx context.Context, transaction string) (*models.Order, error) {
+
+	// транзакция платежа - тоже uuid
+	transactionUUID, err := utils.ValidateUUID(transaction)
+	if err != nil {
+		return nil, fmt.Errorf("[GetOrderByTransaction|validate]: %w", ErrInvalidUUID)
+	}
+
+	respOrder, exist := s.Cache.GetByTransaction(transactionUUID)
+	if exist {
+		slog.Info("got order from cache", "transaction", transactionUUID)
+		return respOrder, nil
+	}
+
+	respOrder, err = s.Repo.GetOrderByTransaction(ctx, transactionUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheOrder(respOrder)
+	return respOrder, nil
+}
+
+// GetCustomerOrders возвращает страницу заказов покупателя, новые первыми
+func (s *serviceOrder) GetCustomerOrders(ctx context.Context, customerID string, query *models.CustomerOrdersQuery) (*models.OrderPage, error) {
+	filter, err := newOrderFilter(&models.OrderListQuery{
+		Limit:      query.Limit,
+		Cursor:     query.Cursor,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// полный список покупателя из кэша делится на страницы без обращения к БД
+	orders, exist := s.Cache.GetByCustomer(customerID)
+	if exist {
+		slog.Info("got customer orders from cache", "customer_id", customerID)
+		return newOrderPage(ordersAfter(orders, filter.After), filter, filter.Limit)
+	}
+
+	// запросим на один заказ больше, чтобы узнать, есть ли следующая страница
+	orders, err = s.Repo.GetOrdersByCustomer(ctx, customerID, filter.Limit+1, filter.After)
+	if err != nil {
+		return nil, err
+	}
+
+	// первая страница без продолжения - это все заказы покупателя
+	if filter.After == nil && len(orders) <= filter.Limit {
+		s.Cache.SetCustomerOrders(customerID, orders)
+	}
+	return newOrderPage(orders, filter, filter.Limit)
+}
+
+// ordersAfter заказы списка, новые первыми, которые идут после курсора; пустой курсор - весь список
+func ordersAfter(orders []*models.Order, after *models.OrderCursor) []*models.Order {
+	if after == nil {
+		return orders
+	}
+	last := &models.Order{OrderUID: after.OrderUID, DateCreated: after.DateCreated}
+	for i, order := range orders {
+		if last.CreatedAfter(order) {
+			return orders[i:]
+		}
+	}
+	return orders[len(orders):]
+}
@@ -10,9 +10,15 @@
 package mock_service
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
+	cache "github.com/orders_api/internal/database/cache"
+	postgres "github.com/orders_api/internal/database/postgres"
+	ingest "github.com/orders_api/internal/ingest"
 	models "github.com/orders_api/internal/models"
+	service "github.com/orders_api/internal/service"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,46 +46,239 @@ func (m *MockServiceOrder) EXPECT() *MockServiceOrderMockRecorder {
 	return m.recorder
 }
 
+// CacheStats mocks base method.
+func (m *MockServiceOrder) CacheStats() cache.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStats")
+	ret0, _ := ret[0].(cache.Stats)
+	return ret0
+}
+
+// CacheStats indicates an expected call of CacheStats.
+func (mr *MockServiceOrderMockRecorder) CacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockServiceOrder)(nil).CacheStats))
+}
+
+// ChangeOrderStatus mocks base method.
+func (m *MockServiceOrder) ChangeOrderStatus(ctx context.Context, id string, req *models.StatusChangeRequest) (*models.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeOrderStatus", ctx, id, req)
+	ret0, _ := ret[0].(*models.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeOrderStatus indicates an expected call of ChangeOrderStatus.
+func (mr *MockServiceOrderMockRecorder) ChangeOrderStatus(ctx, id, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeOrderStatus", reflect.TypeOf((*MockServiceOrder)(nil).ChangeOrderStatus), ctx, id, req)
+}
+
+// CreateOrder mocks base method.
+func (m *MockServiceOrder) CreateOrder(ctx context.Context, order *models.Order, idempotencyKey string) (*models.Order, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, order, idempotencyKey)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockServiceOrderMockRecorder) CreateOrder(ctx, order, idempotencyKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockServiceOrder)(nil).CreateOrder), ctx, order, idempotencyKey)
+}
+
+// DBStats mocks base method.
+func (m *MockServiceOrder) DBStats() postgres.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DBStats")
+	ret0, _ := ret[0].(postgres.DBStats)
+	return ret0
+}
+
+// DBStats indicates an expected call of DBStats.
+func (mr *MockServiceOrderMockRecorder) DBStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DBStats", reflect.TypeOf((*MockServiceOrder)(nil).DBStats))
+}
+
+// ExportOrders mocks base method.
+func (m *MockServiceOrder) ExportOrders(query *models.OrderListQuery, batchSize int) (service.OrderExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportOrders", query, batchSize)
+	ret0, _ := ret[0].(service.OrderExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportOrders indicates an expected call of ExportOrders.
+func (mr *MockServiceOrderMockRecorder) ExportOrders(query, batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportOrders", reflect.TypeOf((*MockServiceOrder)(nil).ExportOrders), query, batchSize)
+}
+
+// GetCustomerOrders mocks base method.
+func (m *MockServiceOrder) GetCustomerOrders(ctx context.Context, customerID string, query *models.CustomerOrdersQuery) (*models.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerOrders", ctx, customerID, query)
+	ret0, _ := ret[0].(*models.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerOrders indicates an expected call of GetCustomerOrders.
+func (mr *MockServiceOrderMockRecorder) GetCustomerOrders(ctx, customerID, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerOrders", reflect.TypeOf((*MockServiceOrder)(nil).GetCustomerOrders), ctx, customerID, query)
+}
+
+// GetOrderByTrack mocks base method.
+func (m *MockServiceOrder) GetOrderByTrack(ctx context.Context, track string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByTrack", ctx, track)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByTrack indicates an expected call of GetOrderByTrack.
+func (mr *MockServiceOrderMockRecorder) GetOrderByTrack(ctx, track any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByTrack", reflect.TypeOf((*MockServiceOrder)(nil).GetOrderByTrack), ctx, track)
+}
+
+// GetOrderByTransaction mocks base method.
+func (m *MockServiceOrder) GetOrderByTransaction(ctx context.Context, transaction string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByTransaction", ctx, transaction)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByTransaction indicates an expected call of GetOrderByTransaction.
+func (mr *MockServiceOrderMockRecorder) GetOrderByTransaction(ctx, transaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByTransaction", reflect.TypeOf((*MockServiceOrder)(nil).GetOrderByTransaction), ctx, transaction)
+}
+
 // GetOrderByUID mocks base method.
-func (m *MockServiceOrder) GetOrderByUID(id string) (*models.Order, error) {
+func (m *MockServiceOrder) GetOrderByUID(ctx context.Context, id string) (*models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrderByUID", id)
+	ret := m.ctrl.Call(m, "GetOrderByUID", ctx, id)
 	ret0, _ := ret[0].(*models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrderByUID indicates an expected call of GetOrderByUID.
-func (mr *MockServiceOrderMockRecorder) GetOrderByUID(id any) *gomock.Call {
+func (mr *MockServiceOrderMockRecorder) GetOrderByUID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByUID", reflect.TypeOf((*MockServiceOrder)(nil).GetOrderByUID), ctx, id)
+}
+
+// GetStatusHistory mocks base method.
+func (m *MockServiceOrder) GetStatusHistory(ctx context.Context, id string) ([]models.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", ctx, id)
+	ret0, _ := ret[0].([]models.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockServiceOrderMockRecorder) GetStatusHistory(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByUID", reflect.TypeOf((*MockServiceOrder)(nil).GetOrderByUID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockServiceOrder)(nil).GetStatusHistory), ctx, id)
+}
+
+// ImportOrders mocks base method.
+func (m *MockServiceOrder) ImportOrders(ctx context.Context, dec ingest.Decoder, batchSize, workers, maxLines int) (*models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportOrders", ctx, dec, batchSize, workers, maxLines)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportOrders indicates an expected call of ImportOrders.
+func (mr *MockServiceOrderMockRecorder) ImportOrders(ctx, dec, batchSize, workers, maxLines any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportOrders", reflect.TypeOf((*MockServiceOrder)(nil).ImportOrders), ctx, dec, batchSize, workers, maxLines)
+}
+
+// ListOrders mocks base method.
+func (m *MockServiceOrder) ListOrders(ctx context.Context, query *models.OrderListQuery) (*models.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, query)
+	ret0, _ := ret[0].(*models.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockServiceOrderMockRecorder) ListOrders(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockServiceOrder)(nil).ListOrders), ctx, query)
 }
 
 // Recover mocks base method.
-func (m *MockServiceOrder) Recover() error {
+func (m *MockServiceOrder) Recover(limit int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Recover")
+	ret := m.ctrl.Call(m, "Recover", limit)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Recover indicates an expected call of Recover.
-func (mr *MockServiceOrderMockRecorder) Recover() *gomock.Call {
+func (mr *MockServiceOrderMockRecorder) Recover(limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recover", reflect.TypeOf((*MockServiceOrder)(nil).Recover))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recover", reflect.TypeOf((*MockServiceOrder)(nil).Recover), limit)
+}
+
+// RecoverSince mocks base method.
+func (m *MockServiceOrder) RecoverSince(watermark, takenAt time.Time, limit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverSince", watermark, takenAt, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverSince indicates an expected call of RecoverSince.
+func (mr *MockServiceOrderMockRecorder) RecoverSince(watermark, takenAt, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverSince", reflect.TypeOf((*MockServiceOrder)(nil).RecoverSince), watermark, takenAt, limit)
 }
 
 // SetOrder mocks base method.
-func (m *MockServiceOrder) SetOrder(order *models.Order) (*models.Order, error) {
+func (m *MockServiceOrder) SetOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetOrder", order)
+	ret := m.ctrl.Call(m, "SetOrder", ctx, order)
 	ret0, _ := ret[0].(*models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SetOrder indicates an expected call of SetOrder.
-func (mr *MockServiceOrderMockRecorder) SetOrder(order any) *gomock.Call {
+func (mr *MockServiceOrderMockRecorder) SetOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrder", reflect.TypeOf((*MockServiceOrder)(nil).SetOrder), ctx, order)
+}
+
+// SetOrders mocks base method.
+func (m *MockServiceOrder) SetOrders(ctx context.Context, orders []*models.Order) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrders", ctx, orders)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrders indicates an expected call of SetOrders.
+func (mr *MockServiceOrderMockRecorder) SetOrders(ctx, orders any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrder", reflect.TypeOf((*MockServiceOrder)(nil).SetOrder), order)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrders", reflect.TypeOf((*MockServiceOrder)(nil).SetOrders), ctx, orders)
 }
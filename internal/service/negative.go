@@ -0,0 +1,61 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// negativeCache запоминает на ttl заказы, которых нет в БД, чтобы повторные запросы неизвестных uuid не доходили до БД
+type negativeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]time.Time
+	now     func() time.Time
+}
+
+func newNegativeCache(ttl time.Duration) *negativeCache {
+	return &negativeCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (n *negativeCache) Add(id uuid.UUID) {
+	if n.ttl <= 0 {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// заодно уберем истекшие записи, чтобы поток разных неизвестных uuid не копил память
+	now := n.now()
+	for k, expiresAt := range n.entries {
+		if now.After(expiresAt) {
+			delete(n.entries, k)
+		}
+	}
+	n.entries[id] = now.Add(n.ttl)
+}
+
+func (n *negativeCache) Has(id uuid.UUID) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	expiresAt, ok := n.entries[id]
+	if !ok {
+		return false
+	}
+	if n.now().After(expiresAt) {
+		delete(n.entries, id)
+		return false
+	}
+	return true
+}
+
+func (n *negativeCache) Delete(id uuid.UUID) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.entries, id)
+}
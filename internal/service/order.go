@@ -5,39 +5,75 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/orders_api/internal/database/cache"
+	"github.com/orders_api/internal/database/postgres"
+	"github.com/orders_api/internal/ingest"
 	"github.com/orders_api/internal/models"
 	"github.com/orders_api/internal/repository"
+	"github.com/orders_api/internal/tracing"
 	"github.com/orders_api/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
-	ErrInvalidUUID  = errors.New("invalid uuid order's fromat")
+	ErrInvalidUUID = errors.New("invalid uuid order's fromat")
+	// ErrValidateJSON оборачивается вместе с *utils.ValidationError: поля, не прошедшие проверку, достаются через utils.FieldErrors
 	ErrValidateJSON = errors.New("invalid values in JSON request")
 )
 
 type ServiceOrder interface {
-	GetOrderByUID(id string) (*models.Order, error)
-	SetOrder(order *models.Order) (*models.Order, error)
-	Recover() error
+	GetOrderByUID(ctx context.Context, id string) (*models.Order, error)
+	SetOrder(ctx context.Context, order *models.Order) (*models.Order, error)
+	CreateOrder(ctx context.Context, order *models.Order, idempotencyKey string) (*models.Order, bool, error)
+	SetOrders(ctx context.Context, orders []*models.Order) ([]error, error)
+	ImportOrders(ctx context.Context, dec ingest.Decoder, batchSize, workers, maxLines int) (*models.ImportReport, error)
+	ListOrders(ctx context.Context, query *models.OrderListQuery) (*models.OrderPage, error)
+	ExportOrders(query *models.OrderListQuery, batchSize int) (OrderExport, error)
+	GetOrderByTrack(ctx context.Context, track string) (*models.Order, error)
+	GetOrderByTransaction(ctx context.Context, transaction string) (*models.Order, error)
+	GetCustomerOrders(ctx context.Context, customerID string, query *models.CustomerOrdersQuery) (*models.OrderPage, error)
+	ChangeOrderStatus(ctx context.Context, id string, req *models.StatusChangeRequest) (*models.StatusChange, error)
+	GetStatusHistory(ctx context.Context, id string) ([]models.StatusChange, error)
+	CacheStats() cache.Stats
+	DBStats() postgres.DBStats
+	Recover(limit int) error
+	RecoverSince(watermark, takenAt time.Time, limit int) error
 }
 
 type serviceOrder struct {
 	Repo  repository.OrderRepository
 	Cache cache.Cache
 	ctx   context.Context
+
+	// загрузки одного заказа из БД при одновременных промахах кэша
+	loads    singleflight.Group
+	notFound *negativeCache
 }
 
-func NewServiceOrder(r repository.OrderRepository, c cache.Cache, ct context.Context) *serviceOrder {
+// NewServiceOrder создает сервис заказов. notFoundTTL - сколько помнить, что заказа нет в БД, 0 - не запоминать
+func NewServiceOrder(r repository.OrderRepository, c cache.Cache, notFoundTTL time.Duration, ct context.Context) *serviceOrder {
+	// измененные и удаленные в БД заказы убираем из кэша, актуальная версия загрузится при следующем обращении
+	r.OnOrderChange(func(uid uuid.UUID) {
+		c.Delete(uid)
+	})
+
 	return &serviceOrder{
-		Repo:  r,
-		Cache: c,
-		ctx:   ct,
+		Repo:     r,
+		Cache:    c,
+		ctx:      ct,
+		notFound: newNegativeCache(notFoundTTL),
 	}
 }
 
-func (s *serviceOrder) GetOrderByUID(id string) (*models.Order, error) {
+func (s *serviceOrder) GetOrderByUID(ctx context.Context, id string) (_ *models.Order, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "serviceOrder.GetOrderByUID", trace.WithAttributes(attribute.String("order.uid", id)))
+	defer func() { tracing.End(span, err) }()
 
 	// провалидировать uid
 	order_uuid, err := utils.ValidateUUID(id)
@@ -47,47 +83,218 @@ func (s *serviceOrder) GetOrderByUID(id string) (*models.Order, error) {
 
 	// сначала обращаемся к кэшу
 	respOrder, exist := s.Cache.Get(order_uuid)
+	span.SetAttributes(attribute.Bool("cache.hit", exist))
 	if exist {
 		slog.Info("got order from cache", "order_uuid", order_uuid)
 		return respOrder, nil
 	}
 
-	// запрос к БД, если в кэше нет
-	respOrder, err = s.Repo.GetOrderByUID(s.ctx, order_uuid)
+	// недавно заказа не было в БД - не спрашиваем ее повторно
+	if s.notFound.Has(order_uuid) {
+		return nil, fmt.Errorf("[GetOrderByUID|negative cache]: %w", repository.ErrOrderNotFoundByUUID)
+	}
+
+	// запрос к БД, если в кэше нет; одновременные промахи по одному заказу ждут одну загрузку.
+	// Загрузку не прерывает отмена запроса, который ее начал: ее результат ждут и другие
+	loadCtx := context.WithoutCancel(ctx)
+	loaded, err, shared := s.loads.Do(order_uuid.String(), func() (any, error) {
+		order, err := s.Repo.GetOrderByUID(loadCtx, order_uuid)
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderNotFoundByUUID) {
+				s.notFound.Add(order_uuid)
+			}
+			return nil, err
+		}
+
+		// запишем этот заказ в кэш
+		s.Cache.Set(order_uuid, order)
+		slog.Info("set order into cache", "order_uuid", order_uuid)
+		return order, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// запишем этот заказ в кэш
-	s.Cache.Set(order_uuid, respOrder)
-	slog.Info("set order into cache", "order_uuid", order_uuid)
-	return respOrder, nil
+	if shared {
+		slog.Debug("shared order load from DB", "order_uuid", order_uuid)
+	}
+	return loaded.(*models.Order), nil
 
 }
 
-func (s *serviceOrder) SetOrder(order *models.Order) (*models.Order, error) {
+func (s *serviceOrder) SetOrder(ctx context.Context, order *models.Order) (_ *models.Order, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "serviceOrder.SetOrder", trace.WithAttributes(attribute.String("order.uid", order.OrderUID.String())))
+	defer func() { tracing.End(span, err) }()
 
 	// провалидируем структуру на ограничения полей
-	err := utils.VaildateStructs(order)
+	err = utils.VaildateStructs(order)
 	if err != nil {
-		return nil, fmt.Errorf("[SetOrder|validate JSON]: %w", ErrValidateJSON)
+		return nil, fmt.Errorf("[SetOrder|validate JSON]: %w: %w", ErrValidateJSON, err)
 	}
+	if order.IsVersioned() {
+		return s.upsertOrder(ctx, order)
+	}
+	err = initStatus(order)
+	if err != nil {
+		return nil, fmt.Errorf("[SetOrder|init status]: %w", err)
+	}
+	return s.insertOrder(ctx, order, nil)
+}
 
+// insertOrder сохраняет проверенный новый заказ; key - ключ идемпотентности, который сохраняется в той же транзакции, nil - без ключа
+func (s *serviceOrder) insertOrder(ctx context.Context, order *models.Order, key *models.IdempotencyKey) (*models.Order, error) {
 	// запрос к БД
-	newOrder, err := s.Repo.InsertOrder(s.ctx, order)
+	var newOrder *models.Order
+	var err error
+	if key == nil {
+		newOrder, err = s.Repo.InsertOrder(ctx, order)
+	} else {
+		newOrder, err = s.Repo.InsertOrderWithKey(ctx, order, key)
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	// новый заказ сразу попадает в кэш, чтобы закэшированные списки заказов покупателей оставались полными
+	s.cacheOrder(newOrder)
 	return newOrder, nil
 }
 
-func (s *serviceOrder) Recover() error {
-	orders, err := s.Repo.GetAllOrders(s.ctx)
+// SetOrders сохраняет пачку новых заказов одной транзакцией. Заказы с версией в пачку не входят:
+// upsert заменил бы сохраненный заказ вне транзакции пачки, поэтому они отклоняются и применяются через SetOrder.
+// Возвращает ошибки по каждому заказу (в порядке orders) и общую ошибку, если пачку записать не удалось
+func (s *serviceOrder) SetOrders(ctx context.Context, orders []*models.Order) (_ []error, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "serviceOrder.SetOrders", trace.WithAttributes(attribute.Int("orders.count", len(orders))))
+	defer func() { tracing.End(span, err) }()
+
+	orderErrs := make([]error, len(orders))
+
+	// невалидные заказы в БД не отправляем
+	valid := make([]*models.Order, 0, len(orders))
+	validIdx := make([]int, 0, len(orders))
+	for i, order := range orders {
+		err := checkNewOrder(order)
+		if err != nil {
+			orderErrs[i] = fmt.Errorf("[SetOrders|check order]: %w", err)
+			continue
+		}
+		valid = append(valid, order)
+		validIdx = append(validIdx, i)
+	}
+
+	insertErrs, err := s.insertOrders(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	for i, insertErr := range insertErrs {
+		orderErrs[validIdx[i]] = insertErr
+	}
+	return orderErrs, nil
+}
+
+// insertOrders сохраняет пачку проверенных новых заказов одной транзакцией и кладет сохраненные в кэш
+func (s *serviceOrder) insertOrders(ctx context.Context, orders []*models.Order) ([]error, error) {
+	// запрос к БД
+	insertErrs, err := s.Repo.InsertOrders(ctx, orders)
 	if err != nil {
-		return fmt.Errorf("[Recoder| getAllOrders]: %w", err)
+		return nil, err
 	}
 
+	for i, insertErr := range insertErrs {
+		if insertErr == nil {
+			s.cacheOrder(orders[i])
+		}
+	}
+	return insertErrs, nil
+}
+
+// upsertOrder сохраняет заказ или заменяет сохраненный, если пришедшая версия новее.
+// Устаревшая версия игнорируется без ошибки, чтобы повторная доставка сообщения не уходила в DLQ
+func (s *serviceOrder) upsertOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
+	applied, err := s.Repo.UpsertOrder(ctx, order, func(from, to models.OrderStatus) error {
+		// заказа еще не было - проверяем начальный статус
+		if from == "" {
+			return initStatus(order)
+		}
+		if !CanTransition(from, to) {
+			return fmt.Errorf("[SetOrder|check transition %s -> %s]: %w", from, to, ErrInvalidStatusTransition)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		slog.Info("stale order version ignored", "order_uuid", order.OrderUID, "version", order.Version)
+		return order, nil
+	}
+
+	// репозиторий уже убрал предыдущую версию из кэша - сразу положим новую
+	s.cacheOrder(order)
+	return order, nil
+}
+
+// cacheOrder кладет сохраненный заказ в кэш и забывает, что его не было в БД
+func (s *serviceOrder) cacheOrder(order *models.Order) {
+	s.notFound.Delete(order.OrderUID)
+	s.Cache.Set(order.OrderUID, order)
+}
+
+// checkNewOrder проверяет заказ, который создается, а не заменяет сохраненный: поля заказа,
+// отсутствие версии (заменить заказ можно только новой версией из Kafka) и начальный статус
+func checkNewOrder(order *models.Order) error {
+	err := utils.VaildateStructs(order)
+	if err != nil {
+		return fmt.Errorf("[checkNewOrder|validate JSON]: %w: %w", ErrValidateJSON, err)
+	}
+	if order.IsVersioned() {
+		return fmt.Errorf("[checkNewOrder|versioned order]: %w: %w", ErrValidateJSON, versionFieldsError(order))
+	}
+	return initStatus(order)
+}
+
+// initStatus задает статус нового заказа: жизненный цикл всегда начинается с created,
+// остальные статусы заказ получает только переходами, поэтому другой начальный статус - ошибка проверки
+func initStatus(order *models.Order) error {
+	if order.Status != "" && order.Status != models.StatusCreated {
+		return fmt.Errorf("[initStatus|status %q]: %w: %w", order.Status, ErrValidateJSON, &utils.ValidationError{
+			Fields: []models.FieldError{{
+				Field:   "status",
+				Rule:    "eq",
+				Param:   string(models.StatusCreated),
+				Message: "must be " + string(models.StatusCreated) + " for a new order",
+			}},
+		})
+	}
+	order.Status = models.StatusCreated
+	return nil
+}
+
+// Recover загружает в кэш limit последних по date_created заказов
+func (s *serviceOrder) Recover(limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	orders, err := s.Repo.ListOrders(s.ctx, &models.OrderFilter{
+		Sort:  models.SortByDateCreated,
+		Desc:  true,
+		Limit: limit,
+	})
+	if err != nil {
+		return fmt.Errorf("[Recover| list recent orders]: %w", err)
+	}
+
+	// самые новые заказы сохраняем последними, чтобы они вытеснялись последними
+	slices.Reverse(orders)
 	s.Cache.SetAll(orders)
 
 	return nil
 }
+
+func (s *serviceOrder) CacheStats() cache.Stats {
+	return s.Cache.Stats()
+}
+
+func (s *serviceOrder) DBStats() postgres.DBStats {
+	return s.Repo.DBStats()
+}
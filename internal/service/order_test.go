@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/database/cache"
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/repository"
+	mock_repository "github.com/orders_api/internal/repository/mocks"
+	"github.com/orders_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestService(t *testing.T, notFoundTTL time.Duration) (*serviceOrder, *mock_repository.MockOrderRepository) {
+	ctrl := gomock.NewController(t)
+	repo := mock_repository.NewMockOrderRepository(ctrl)
+	repo.EXPECT().OnOrderChange(gomock.Any())
+
+	return NewServiceOrder(repo, cache.NewOrderCacher(&cache.CacheConfig{}), notFoundTTL, context.Background()), repo
+}
+
+func TestGetOrderByUID_CoalescesConcurrentMisses(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	uid := uuid.Must(uuid.NewV4())
+
+	const callers = 10
+	var started sync.WaitGroup
+	started.Add(callers)
+	release := make(chan struct{})
+
+	repo.EXPECT().GetOrderByUID(gomock.Any(), uid).
+		DoAndReturn(func(context.Context, uuid.UUID) (*models.Order, error) {
+			<-release
+			return &models.Order{OrderUID: uid}, nil
+		}).Times(1)
+
+	var done sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			order, err := s.GetOrderByUID(context.Background(), uid.String())
+			assert.NoError(t, err)
+			assert.Equal(t, uid, order.OrderUID)
+		}()
+	}
+
+	// дадим всем запросам дойти до загрузки, прежде чем ее завершить
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	_, ok := s.Cache.Get(uid)
+	assert.True(t, ok)
+}
+
+func TestGetOrderByUID_NegativeCache(t *testing.T) {
+	s, repo := newTestService(t, time.Minute)
+	uid := uuid.Must(uuid.NewV4())
+
+	repo.EXPECT().GetOrderByUID(gomock.Any(), uid).
+		Return(nil, repository.ErrOrderNotFoundByUUID).Times(1)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.GetOrderByUID(context.Background(), uid.String())
+		assert.ErrorIs(t, err, repository.ErrOrderNotFoundByUUID)
+	}
+
+	// сохраненный заказ перестает считаться отсутствующим
+	order := &models.Order{OrderUID: uid}
+	s.cacheOrder(order)
+	s.Cache.Delete(uid)
+
+	repo.EXPECT().GetOrderByUID(gomock.Any(), uid).Return(order, nil)
+	got, err := s.GetOrderByUID(context.Background(), uid.String())
+	assert.NoError(t, err)
+	assert.Equal(t, order, got)
+}
+
+func TestRecoverSince_DropsChangedAndLoadsNewOrders(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	takenAt := time.Now()
+	watermark := takenAt.Add(-time.Hour)
+
+	changed := &models.Order{OrderUID: uuid.Must(uuid.NewV4()), DateCreated: watermark.Add(-time.Hour)}
+	kept := &models.Order{OrderUID: uuid.Must(uuid.NewV4()), DateCreated: watermark}
+	s.Cache.SetAll([]*models.Order{changed, kept})
+	created := &models.Order{OrderUID: uuid.Must(uuid.NewV4()), DateCreated: takenAt}
+
+	repo.EXPECT().GetChangedOrderUIDs(gomock.Any(), takenAt.Add(-snapshotClockSkew)).
+		Return([]uuid.UUID{changed.OrderUID}, nil)
+	repo.EXPECT().ListOrders(gomock.Any(), &models.OrderFilter{
+		DateFrom: &watermark,
+		Sort:     models.SortByDateCreated,
+		Desc:     true,
+		Limit:    10,
+	}).Return([]*models.Order{created, kept}, nil)
+
+	assert.NoError(t, s.RecoverSince(watermark, takenAt, 10))
+
+	_, ok := s.Cache.Get(changed.OrderUID)
+	assert.False(t, ok)
+	_, ok = s.Cache.Get(kept.OrderUID)
+	assert.True(t, ok)
+	_, ok = s.Cache.Get(created.OrderUID)
+	assert.True(t, ok)
+}
+
+func TestSetOrders_RejectsVersionedOrder(t *testing.T) {
+	s, repo := newTestService(t, 0)
+	order, versioned := testOrder(), testOrder()
+	versioned.Version = 2
+
+	// заказ с версией не пишется ни в пачке, ни отдельно от ее транзакции
+	repo.EXPECT().InsertOrders(gomock.Any(), []*models.Order{order}).Return([]error{nil}, nil)
+
+	orderErrs, err := s.SetOrders(context.Background(), []*models.Order{versioned, order})
+	require.NoError(t, err)
+	assert.ErrorIs(t, orderErrs[0], ErrValidateJSON)
+	assert.Equal(t, []models.FieldError{{Field: "version", Rule: "excluded", Message: "must not be set when creating an order"}}, utils.FieldErrors(orderErrs[0]))
+	assert.NoError(t, orderErrs[1])
+}
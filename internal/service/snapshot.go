@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/orders_api/internal/models"
+)
+
+// snapshotClockSkew запас на расхождение часов сервиса и БД при поиске изменений после снапшота
+const snapshotClockSkew = time.Minute
+
+// RecoverSince догружает кэш, восстановленный из снапшота: удаляет заказы, измененные после takenAt,
+// и загружает до limit заказов, созданных начиная с watermark
+func (s *serviceOrder) RecoverSince(watermark, takenAt time.Time, limit int) error {
+	changed, err := s.Repo.GetChangedOrderUIDs(s.ctx, takenAt.Add(-snapshotClockSkew))
+	if err != nil {
+		return fmt.Errorf("[RecoverSince| get changed orders]: %w", err)
+	}
+	for _, uid := range changed {
+		s.Cache.Delete(uid)
+	}
+
+	if limit <= 0 {
+		return nil
+	}
+
+	orders, err := s.Repo.ListOrders(s.ctx, &models.OrderFilter{
+		DateFrom: &watermark,
+		Sort:     models.SortByDateCreated,
+		Desc:     true,
+		Limit:    limit,
+	})
+	if err != nil {
+		return fmt.Errorf("[RecoverSince| list new orders]: %w", err)
+	}
+
+	slices.Reverse(orders)
+	s.Cache.SetAll(orders)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/utils"
+)
+
+var (
+	ErrInvalidStatus           = errors.New("unknown order status")
+	ErrInvalidStatusTransition = errors.New("order status transition is not allowed")
+)
+
+// orderTransitions допустимые переходы между статусами заказа.
+// cancelled и returned - конечные статусы
+var orderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.StatusCreated:    {models.StatusPaid, models.StatusCancelled},
+	models.StatusPaid:       {models.StatusAssembling, models.StatusCancelled},
+	models.StatusAssembling: {models.StatusShipped, models.StatusCancelled},
+	models.StatusShipped:    {models.StatusDelivered, models.StatusReturned},
+	models.StatusDelivered:  {models.StatusReturned},
+	models.StatusCancelled:  {},
+	models.StatusReturned:   {},
+}
+
+func IsKnownStatus(status models.OrderStatus) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// CanTransition сообщает, разрешен ли переход заказа из статуса from в статус to
+func CanTransition(from, to models.OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *serviceOrder) ChangeOrderStatus(ctx context.Context, id string, req *models.StatusChangeRequest) (*models.StatusChange, error) {
+
+	// провалидировать uid
+	order_uuid, err := utils.ValidateUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("[ChangeOrderStatus|validate]: %w", ErrInvalidUUID)
+	}
+
+	err = utils.VaildateStructs(req)
+	if err != nil {
+		return nil, fmt.Errorf("[ChangeOrderStatus|validate JSON]: %w: %w", ErrValidateJSON, err)
+	}
+
+	if !IsKnownStatus(req.Status) {
+		return nil, fmt.Errorf("[ChangeOrderStatus|validate status %q]: %w", req.Status, ErrInvalidStatus)
+	}
+
+	current, err := s.Repo.GetOrderStatus(ctx, order_uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanTransition(current, req.Status) {
+		return nil, fmt.Errorf("[ChangeOrderStatus|%s -> %s]: %w", current, req.Status, ErrInvalidStatusTransition)
+	}
+
+	change := &models.StatusChange{
+		OrderUID:   order_uuid,
+		FromStatus: current,
+		ToStatus:   req.Status,
+		Actor:      req.Actor,
+	}
+
+	err = s.Repo.UpdateOrderStatus(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("order status changed",
+		"order_uuid", order_uuid,
+		"from", current,
+		"to", req.Status,
+		"actor", req.Actor)
+	return change, nil
+}
+
+func (s *serviceOrder) GetStatusHistory(ctx context.Context, id string) ([]models.StatusChange, error) {
+
+	// провалидировать uid
+	order_uuid, err := utils.ValidateUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("[GetStatusHistory|validate]: %w", ErrInvalidUUID)
+	}
+
+	return s.Repo.GetStatusHistory(ctx, order_uuid)
+}
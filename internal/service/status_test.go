@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/orders_api/internal/models"
+	"github.com/orders_api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		Name    string
+		From    models.OrderStatus
+		To      models.OrderStatus
+		Allowed bool
+	}{
+		{Name: "Created_to_paid", From: models.StatusCreated, To: models.StatusPaid, Allowed: true},
+		{Name: "Created_to_cancelled", From: models.StatusCreated, To: models.StatusCancelled, Allowed: true},
+		{Name: "Created_to_shipped", From: models.StatusCreated, To: models.StatusShipped, Allowed: false},
+		{Name: "Paid_to_assembling", From: models.StatusPaid, To: models.StatusAssembling, Allowed: true},
+		{Name: "Assembling_to_shipped", From: models.StatusAssembling, To: models.StatusShipped, Allowed: true},
+		{Name: "Shipped_to_cancelled", From: models.StatusShipped, To: models.StatusCancelled, Allowed: false},
+		{Name: "Shipped_to_delivered", From: models.StatusShipped, To: models.StatusDelivered, Allowed: true},
+		{Name: "Delivered_to_returned", From: models.StatusDelivered, To: models.StatusReturned, Allowed: true},
+		{Name: "Cancelled_is_final", From: models.StatusCancelled, To: models.StatusPaid, Allowed: false},
+		{Name: "Returned_is_final", From: models.StatusReturned, To: models.StatusDelivered, Allowed: false},
+		{Name: "Same_status", From: models.StatusPaid, To: models.StatusPaid, Allowed: false},
+		{Name: "Unknown_status", From: "lost", To: models.StatusPaid, Allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Allowed, CanTransition(tt.From, tt.To))
+		})
+	}
+}
+
+func TestIsKnownStatus(t *testing.T) {
+	assert.True(t, IsKnownStatus(models.StatusAssembling))
+	assert.False(t, IsKnownStatus("lost"))
+}
+
+func TestSetOrder_InitialStatus(t *testing.T) {
+	statusField := []models.FieldError{{Field: "status", Rule: "eq", Param: "created", Message: "must be created for a new order"}}
+
+	t.Run("Empty_status_starts_created", func(t *testing.T) {
+		s, repo := newTestService(t, 0)
+		order := testOrder()
+		repo.EXPECT().InsertOrder(gomock.Any(), order).Return(order, nil)
+
+		saved, err := s.SetOrder(context.Background(), order)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCreated, saved.Status)
+	})
+
+	t.Run("Other_status_rejected", func(t *testing.T) {
+		s, _ := newTestService(t, 0)
+		order := testOrder()
+		order.Status = models.StatusDelivered
+
+		_, err := s.SetOrder(context.Background(), order)
+		assert.ErrorIs(t, err, ErrValidateJSON)
+		assert.Equal(t, statusField, utils.FieldErrors(err))
+	})
+
+	t.Run("Other_status_rejected_in_batch", func(t *testing.T) {
+		s, repo := newTestService(t, 0)
+		order := testOrder()
+		order.Status = models.StatusCancelled
+		repo.EXPECT().InsertOrders(gomock.Any(), []*models.Order{}).Return([]error{}, nil)
+
+		orderErrs, err := s.SetOrders(context.Background(), []*models.Order{order})
+		require.NoError(t, err)
+		assert.ErrorIs(t, orderErrs[0], ErrValidateJSON)
+	})
+
+	t.Run("New_versioned_order_rejected", func(t *testing.T) {
+		s, repo := newTestService(t, 0)
+		order := testOrder()
+		order.Version = 1
+		order.Status = models.StatusShipped
+		repo.EXPECT().UpsertOrder(gomock.Any(), order, gomock.Any()).
+			DoAndReturn(func(_ context.Context, order *models.Order, checkStatus func(from, to models.OrderStatus) error) (bool, error) {
+				// заказа в БД нет - репозиторий проверяет начальный статус
+				return false, checkStatus("", order.Status)
+			})
+
+		_, err := s.SetOrder(context.Background(), order)
+		assert.ErrorIs(t, err, ErrValidateJSON)
+		assert.Equal(t, statusField, utils.FieldErrors(err))
+	})
+}
@@ -0,0 +1,17 @@
+package tracing
+
+// экспортеры спанов
+const (
+	ExporterNone   = "none"   // спаны не записываются, контекст трассировки только передается дальше
+	ExporterStdout = "stdout" // спаны пишутся в stdout, для отладки
+	ExporterOTLP   = "otlp"   // спаны отправляются в OpenTelemetry collector по OTLP/HTTP
+)
+
+// Config настройки трассировки
+type Config struct {
+	Exporter     string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"otel-collector:4318"` // host:port collector
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" envDefault:"true"`
+	ServiceName  string  `env:"TRACING_SERVICE_NAME" envDefault:"orders_api"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"` // доля новых трасс, которые записываются
+}
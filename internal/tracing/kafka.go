@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headersCarrier читает и пишет заголовки trace context в сообщении Kafka
+type headersCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (h headersCarrier) Get(key string) string {
+	for _, header := range *h.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h headersCarrier) Set(key, value string) {
+	for i, header := range *h.headers {
+		if header.Key == key {
+			(*h.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*h.headers = append(*h.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (h headersCarrier) Keys() []string {
+	keys := make([]string, 0, len(*h.headers))
+	for _, header := range *h.headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+// ExtractMessage возвращает ctx с контекстом трассы из заголовков сообщения
+func ExtractMessage(ctx context.Context, msg *kafka.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headersCarrier{&msg.Headers})
+}
+
+// InjectMessage записывает контекст трассы из ctx в заголовки сообщения
+func InjectMessage(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headersCarrier{&msg.Headers})
+}
+
+// MessageAttributes атрибуты спана, по которым сообщение можно найти в Kafka
+func MessageAttributes(msg *kafka.Message) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", msg.Topic),
+		attribute.String("messaging.kafka.partition", strconv.Itoa(msg.Partition)),
+		attribute.Int64("messaging.kafka.offset", msg.Offset),
+	)
+}
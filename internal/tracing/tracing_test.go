@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+	traceparent   = "00-" + parentTraceID + "-" + parentSpanID + "-01"
+)
+
+// setupTracing подменяет глобальный TracerProvider на записывающий спаны в память
+func setupTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	_, err := Init(context.Background(), &Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(NewProvider(&Config{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return exporter
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestHTTPMiddleware_ContinuesTrace(t *testing.T) {
+	exporter := setupTracing(t)
+
+	app := fiber.New()
+	app.Use(HTTPMiddleware())
+	app.Get("/orders/:order_uid", func(c *fiber.Ctx) error {
+		// обработчик должен получить контекст серверного спана
+		_, span := Tracer().Start(c.UserContext(), "handler")
+		span.End()
+		return fiber.ErrNotFound
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/orders/abc", nil)
+	req.Header.Set("traceparent", traceparent)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	handler, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /orders/:order_uid", server.Name)
+	assert.Equal(t, parentTraceID, server.SpanContext.TraceID().String())
+	assert.Equal(t, parentSpanID, server.Parent.SpanID().String())
+	assert.Equal(t, int64(fiber.StatusNotFound), attr(server, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, server.Status.Code)
+
+	assert.Equal(t, server.SpanContext.SpanID(), handler.Parent.SpanID())
+}
+
+func TestKafkaHeaders_RoundTrip(t *testing.T) {
+	exporter := setupTracing(t)
+
+	ctx, span := Tracer().Start(context.Background(), "produce")
+	msg := kafka.Message{Headers: []kafka.Header{{Key: "event-type", Value: []byte("order.create")}}}
+	InjectMessage(ctx, &msg)
+	InjectMessage(ctx, &msg)
+	span.End()
+
+	// повторная запись не дублирует заголовок
+	var keys []string
+	for _, h := range msg.Headers {
+		keys = append(keys, h.Key)
+	}
+	assert.Equal(t, []string{"event-type", "traceparent"}, keys)
+
+	_, consume := Tracer().Start(ExtractMessage(context.Background(), &msg), "consume")
+	consume.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	assert.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+}
+
+func TestQueryTracer(t *testing.T) {
+	exporter := setupTracing(t)
+	tracer := NewQueryTracer("primary")
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "\n\tselect order_uid from \"order\""})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "postgres SELECT", spans[0].Name)
+	assert.Equal(t, "primary", attr(spans[0], "server.address").AsString())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
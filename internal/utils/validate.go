@@ -1,21 +1,129 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofrs/uuid"
+	"github.com/orders_api/internal/models"
 )
 
+// validate общий валидатор: разобранные теги структур кэшируются в нем, поэтому он создается один раз
+var validate = newValidator()
+
+// newValidator называет поля по тегам json (параметры запроса - по тегам query),
+// чтобы ошибки указывали на поле так, как его прислал клиент
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "query"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return ""
+	})
+	return v
+}
+
+// ValidationError структура не прошла проверку: список полей и нарушенных правил
+type ValidationError struct {
+	Fields []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		rule := f.Rule
+		if f.Param != "" {
+			rule += "=" + f.Param
+		}
+		parts = append(parts, f.Field+": "+rule)
+	}
+	return strings.Join(parts, "; ")
+}
+
 func VaildateStructs[T any](someStruct T) error {
-	validator := validator.New()
-	err := validator.Struct(someStruct)
+	err := validate.Struct(someStruct)
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return fmt.Errorf("[ValidateOrder|validate]: %w", newValidationError(validationErrs))
+	}
 	if err != nil {
 		return fmt.Errorf("[ValidateOrder|validate]: %w", err)
 	}
 	return nil
 }
 
+// FieldErrors поля, не прошедшие проверку VaildateStructs, из цепочки обернутых ошибок err; nil, если их нет
+func FieldErrors(err error) []models.FieldError {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+	return validationErr.Fields
+}
+
+func newValidationError(validationErrs validator.ValidationErrors) *ValidationError {
+	fields := make([]models.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, models.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// fieldPath JSON путь поля: пространство имен валидатора без имени проверяемой структуры,
+// например Order.items[1].sale -> items[1].sale
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// fieldMessage описание нарушенного правила для клиента
+func fieldMessage(fe validator.FieldError) string {
+	// для строк и списков min и max ограничивают длину
+	var length string
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		length = "length "
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "lte":
+		return "must be less than or equal to " + fe.Param()
+	case "min":
+		return length + "must be at least " + fe.Param()
+	case "max":
+		return length + "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "datetime":
+		return "must be a date in format " + fe.Param()
+	default:
+		return "failed on the " + fe.Tag() + " rule"
+	}
+}
+
 func ValidateUUID(id string) (uuid.UUID, error) {
 	resUUID, err := uuid.FromString(id)
 	if err != nil {
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+	id BIGSERIAL PRIMARY KEY,
+	event_id UUID UNIQUE NOT NULL,
+	event_type VARCHAR(64) NOT NULL,
+	aggregate_id UUID NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT now(),
+	sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent ON outbox(id) WHERE sent_at IS NULL;
@@ -0,0 +1,20 @@
+ALTER TABLE "order" ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'created';
+
+CREATE TABLE IF NOT EXISTS order_status_history
+(
+	id BIGSERIAL PRIMARY KEY,
+	order_uid UUID NOT NULL,
+	from_status VARCHAR(32),
+	to_status VARCHAR(32) NOT NULL,
+	actor VARCHAR(255) NOT NULL,
+	changed_at TIMESTAMP NOT NULL DEFAULT now(),
+	FOREIGN KEY (order_uid) REFERENCES "order"(order_uid) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_uid ON order_status_history(order_uid, id);
+
+-- у заказов, созданных до появления статусов, история начинается с created
+INSERT INTO order_status_history(order_uid, to_status, actor, changed_at)
+SELECT o.order_uid, 'created', 'system', o.date_created
+FROM "order" o
+WHERE NOT EXISTS (SELECT 1 FROM order_status_history h WHERE h.order_uid = o.order_uid);
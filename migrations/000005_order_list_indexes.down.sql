@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_payment_bank;
+DROP INDEX IF EXISTS idx_payment_provider;
+DROP INDEX IF EXISTS idx_payment_currency;
+DROP INDEX IF EXISTS idx_payment_amount;
+
+DROP INDEX IF EXISTS idx_order_payment_id;
+DROP INDEX IF EXISTS idx_order_delivery_service;
+DROP INDEX IF EXISTS idx_order_customer_id;
+DROP INDEX IF EXISTS idx_order_date_created;
@@ -0,0 +1,10 @@
+-- индексы для фильтров и keyset пагинации GET /orders
+CREATE INDEX IF NOT EXISTS idx_order_date_created ON "order"(date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_order_customer_id ON "order"(customer_id, date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_order_delivery_service ON "order"(delivery_service, date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_order_payment_id ON "order"(payment_id);
+
+CREATE INDEX IF NOT EXISTS idx_payment_amount ON payment(amount, payment_id);
+CREATE INDEX IF NOT EXISTS idx_payment_currency ON payment(currency);
+CREATE INDEX IF NOT EXISTS idx_payment_provider ON payment(provider);
+CREATE INDEX IF NOT EXISTS idx_payment_bank ON payment(bank);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_payment_transaction ON payment("transaction");
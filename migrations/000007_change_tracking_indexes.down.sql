@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_created_at;
+DROP INDEX IF EXISTS idx_order_status_history_changed_at;
@@ -0,0 +1,3 @@
+-- индексы для поиска заказов, измененных после сохранения снапшота кэша
+CREATE INDEX IF NOT EXISTS idx_order_status_history_changed_at ON order_status_history(changed_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_created_at ON outbox(created_at);
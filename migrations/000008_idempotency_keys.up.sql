@@ -0,0 +1,8 @@
+-- ключи идемпотентности POST /orders: повтор запроса с тем же ключом возвращает уже созданный заказ
+CREATE TABLE IF NOT EXISTS idempotency_key
+(
+	key TEXT PRIMARY KEY,
+	request_hash TEXT NOT NULL,
+	order_uid UUID NOT NULL REFERENCES "order"(order_uid) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL DEFAULT now()
+);